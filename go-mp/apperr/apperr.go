@@ -0,0 +1,95 @@
+// Package apperr defines the errors handlers return to clients. Each error
+// carries an HTTP status and a stable, machine-readable code; HTTPErrorHandler
+// renders it as an RFC 7807 problem document.
+package apperr
+
+import (
+	"errors"
+	"mini-project/validation"
+	"net/http"
+)
+
+type Error struct {
+	Status int
+	Code   string
+	Detail string
+	// Fields lists the failing fields of a VALIDATION_FAILED error.
+	Fields []validation.FieldError
+	// cause is logged for server errors but never shown to clients.
+	cause error
+}
+
+// New defines an error. Handlers usually return one of the predefined errors
+// in codes.go instead.
+func New(status int, code, detail string) *Error {
+	return &Error{Status: status, Code: code, Detail: detail}
+}
+
+// Internal reports a server-side failure. detail is shown to the client and
+// cause is only logged.
+func Internal(cause error, detail string) *Error {
+	return &Error{Status: http.StatusInternalServerError, Code: CodeInternal, Detail: detail, cause: cause}
+}
+
+// From returns err unchanged when it already is an *Error, for example one
+// returned from inside a transaction, and otherwise wraps it with Internal.
+func From(err error, detail string) error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return err
+	}
+
+	return Internal(err, detail)
+}
+
+// Validation reports request fields that failed validation.
+func Validation(fields []validation.FieldError) *Error {
+	return ErrValidationFailed.WithFields(fields)
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Code + ": " + e.Detail + ": " + e.cause.Error()
+	}
+
+	return e.Code + ": " + e.Detail
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is matches errors with the same code, so copies made by the With methods
+// still match the predefined error with errors.Is.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithDetail returns a copy of the error with a more specific message.
+func (e *Error) WithDetail(detail string) *Error {
+	c := *e
+	c.Detail = detail
+	return &c
+}
+
+// WithStatus returns a copy of the error with another HTTP status.
+func (e *Error) WithStatus(status int) *Error {
+	c := *e
+	c.Status = status
+	return &c
+}
+
+// WithFields returns a copy of the error listing the failing fields.
+func (e *Error) WithFields(fields []validation.FieldError) *Error {
+	c := *e
+	c.Fields = fields
+	return &c
+}
+
+// WithCause returns a copy of the error that logs cause.
+func (e *Error) WithCause(cause error) *Error {
+	c := *e
+	c.cause = cause
+	return &c
+}
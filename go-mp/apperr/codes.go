@@ -0,0 +1,76 @@
+package apperr
+
+import "net/http"
+
+// Codes are part of the API contract: clients match on them, so existing
+// codes must never change meaning.
+const (
+	CodeInternal         = "INTERNAL_ERROR"
+	CodeRouteNotFound    = "ROUTE_NOT_FOUND"
+	CodeMethodNotAllowed = "METHOD_NOT_ALLOWED"
+)
+
+// Request errors.
+var (
+	ErrInvalidRequest   = New(http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+	ErrInvalidParameter = New(http.StatusBadRequest, "INVALID_PARAMETER", "Invalid parameter")
+	ErrInvalidID        = New(http.StatusBadRequest, "INVALID_ID", "Invalid ID")
+	ErrInvalidDateRange = New(http.StatusBadRequest, "INVALID_DATE_RANGE", "Invalid date range")
+	ErrValidationFailed = New(http.StatusUnprocessableEntity, "VALIDATION_FAILED", "Validation failed")
+	ErrUnsupportedMedia = New(http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE", "Unsupported Content-Type")
+	ErrIfMatchRequired  = New(http.StatusPreconditionRequired, "IF_MATCH_REQUIRED", "Updates require an If-Match header with the ETag of the resource")
+	ErrStaleVersion     = New(http.StatusPreconditionFailed, "STALE_VERSION", "The resource has changed since it was read; fetch it again and retry")
+)
+
+// Authentication and authorization errors.
+var (
+	ErrUnauthenticated          = New(http.StatusUnauthorized, "UNAUTHENTICATED", "Invalid token credentials")
+	ErrInvalidCredentials       = New(http.StatusUnauthorized, "INVALID_CREDENTIALS", "Invalid email or password")
+	ErrTooManyLoginAttempts     = New(http.StatusTooManyRequests, "TOO_MANY_LOGIN_ATTEMPTS", "Too many failed login attempts. Please try again later.")
+	ErrInvalidRefreshToken      = New(http.StatusUnauthorized, "INVALID_REFRESH_TOKEN", "Invalid or expired refresh token")
+	ErrRefreshTokenReused       = New(http.StatusUnauthorized, "REFRESH_TOKEN_REUSED", "Refresh token reuse detected")
+	ErrInvalidMFAChallenge      = New(http.StatusUnauthorized, "INVALID_MFA_CHALLENGE", "Invalid or expired MFA challenge")
+	ErrInvalidMFACode           = New(http.StatusBadRequest, "INVALID_MFA_CODE", "Invalid authentication code")
+	ErrForbidden                = New(http.StatusForbidden, "FORBIDDEN", "You do not have permission to perform this action")
+	ErrEmailNotVerified         = New(http.StatusForbidden, "EMAIL_NOT_VERIFIED", "Please verify your email address first")
+	ErrMFARequired              = New(http.StatusForbidden, "MFA_REQUIRED", "Your role requires two-factor authentication. Enable it and sign in again.")
+	ErrInvalidVerificationToken = New(http.StatusBadRequest, "INVALID_VERIFICATION_TOKEN", "Invalid or expired verification token")
+	ErrInvalidResetToken        = New(http.StatusBadRequest, "INVALID_RESET_TOKEN", "Invalid or expired reset token")
+	ErrInvalidUnlockToken       = New(http.StatusBadRequest, "INVALID_UNLOCK_TOKEN", "Invalid or expired unlock token")
+)
+
+// Account errors.
+var (
+	ErrUserNotFound         = New(http.StatusNotFound, "USER_NOT_FOUND", "User not found")
+	ErrEmailTaken           = New(http.StatusConflict, "EMAIL_TAKEN", "Email is already registered")
+	ErrEmailAlreadyVerified = New(http.StatusConflict, "EMAIL_ALREADY_VERIFIED", "Email is already verified")
+	ErrMFAAlreadyEnabled    = New(http.StatusConflict, "MFA_ALREADY_ENABLED", "Two-factor authentication is already enabled")
+	ErrMFANotEnabled        = New(http.StatusConflict, "MFA_NOT_ENABLED", "Two-factor authentication is not enabled")
+	ErrMFANotEnrolling      = New(http.StatusConflict, "MFA_ENROLLMENT_NOT_STARTED", "Start two-factor enrollment first")
+	ErrMFARequiredForRole   = New(http.StatusConflict, "MFA_REQUIRED_FOR_ROLE", "Two-factor authentication is required for your role")
+)
+
+// Equipment, rental and wallet errors.
+var (
+	ErrEquipmentNotFound    = New(http.StatusNotFound, "EQUIPMENT_NOT_FOUND", "Equipment not found")
+	ErrEquipmentInUse       = New(http.StatusConflict, "EQUIPMENT_IN_USE", "Equipment has active rentals")
+	ErrEquipmentUnavailable = New(http.StatusConflict, "EQUIPMENT_UNAVAILABLE", "Equipment is not available for booking")
+	ErrRentalNotFound       = New(http.StatusNotFound, "RENTAL_NOT_FOUND", "Rental history not found")
+	ErrRentalOverlap        = New(http.StatusConflict, "RENTAL_OVERLAP", "Equipment is already booked for the requested dates")
+	ErrIllegalTransition    = New(http.StatusConflict, "ILLEGAL_STATUS_TRANSITION", "Illegal rental status transition")
+	ErrRentalActive         = New(http.StatusConflict, "RENTAL_ACTIVE", "Rental is still active; cancel or return it first")
+	ErrRentalNotEditable    = New(http.StatusConflict, "RENTAL_NOT_EDITABLE", "Only requested or confirmed rentals can be changed")
+	ErrRentalPartyChanged   = New(http.StatusConflict, "RENTAL_PARTY_CHANGED", "The user and equipment of a rental cannot be changed; cancel it and book again")
+	ErrInsufficientDeposit  = New(http.StatusPaymentRequired, "INSUFFICIENT_DEPOSIT", "Insufficient deposit amount")
+	ErrWalletInDebt         = New(http.StatusPaymentRequired, "WALLET_IN_DEBT", "Your wallet balance is negative; top up to settle outstanding fees first")
+	ErrInvalidAmount        = New(http.StatusBadRequest, "INVALID_AMOUNT", "Deposit amount must be positive")
+)
+
+// Outbox and idempotency errors.
+var (
+	ErrOutboxMessageNotFound    = New(http.StatusNotFound, "OUTBOX_MESSAGE_NOT_FOUND", "Outbox message not found")
+	ErrOutboxNotRedrivable      = New(http.StatusConflict, "OUTBOX_MESSAGE_NOT_REDRIVABLE", "Only dead messages can be re-driven")
+	ErrIdempotencyKeyTooLong    = New(http.StatusBadRequest, "IDEMPOTENCY_KEY_TOO_LONG", "Idempotency-Key is too long")
+	ErrIdempotencyKeyReused     = New(http.StatusConflict, "IDEMPOTENCY_KEY_REUSED", "Idempotency-Key has already been used with a different request")
+	ErrIdempotencyKeyInProgress = New(http.StatusConflict, "IDEMPOTENCY_KEY_IN_PROGRESS", "A request with this Idempotency-Key is still being processed")
+)
@@ -0,0 +1,101 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"mini-project/validation"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const ContentTypeProblem = "application/problem+json"
+
+// Problem is the RFC 7807 body of every error response.
+type Problem struct {
+	// Type is always about:blank; Code identifies the kind of problem.
+	Type     string `json:"type" example:"about:blank"`
+	Title    string `json:"title" example:"Not Found"`
+	Status   int    `json:"status" example:"404"`
+	Detail   string `json:"detail" example:"Equipment not found"`
+	Instance string `json:"instance" example:"/equipment/42"`
+	Code     string `json:"code" example:"EQUIPMENT_NOT_FOUND"`
+	// TraceID matches the X-Request-ID response header and the server logs.
+	TraceID string                  `json:"trace_id" example:"3fJk2n9QbV1c7xYpL0aRzT5uW8eH4mDs"`
+	Errors  []validation.FieldError `json:"errors,omitempty"`
+}
+
+// HTTPErrorHandler is echo's error handler. It renders errors returned by
+// handlers and middleware as problem documents and logs server errors.
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	appErr := toError(err)
+	traceID := c.Response().Header().Get(echo.HeaderXRequestID)
+
+	if appErr.Status >= http.StatusInternalServerError {
+		logrus.WithFields(logrus.Fields{
+			"trace_id": traceID,
+			"code":     appErr.Code,
+			"method":   c.Request().Method,
+			"path":     c.Request().URL.Path,
+		}).Error(err)
+	}
+
+	problem := Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(appErr.Status),
+		Status:   appErr.Status,
+		Detail:   appErr.Detail,
+		Instance: c.Request().URL.Path,
+		Code:     appErr.Code,
+		TraceID:  traceID,
+		Errors:   appErr.Fields,
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, ContentTypeProblem)
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(appErr.Status)
+	} else {
+		err = c.JSON(appErr.Status, problem)
+	}
+	if err != nil {
+		logrus.Errorf("Error writing error response: %v", err)
+	}
+}
+
+// toError converts errors that did not come from this package, such as
+// echo's routing errors, into an *Error.
+func toError(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		detail := http.StatusText(httpErr.Code)
+		if message, ok := httpErr.Message.(string); ok {
+			detail = message
+		}
+
+		switch httpErr.Code {
+		case http.StatusNotFound:
+			return New(httpErr.Code, CodeRouteNotFound, detail)
+		case http.StatusMethodNotAllowed:
+			return New(httpErr.Code, CodeMethodNotAllowed, detail)
+		case http.StatusBadRequest:
+			return ErrInvalidRequest.WithDetail(detail).WithCause(err)
+		case http.StatusUnauthorized:
+			return ErrUnauthenticated.WithCause(err)
+		}
+		if httpErr.Code < http.StatusInternalServerError {
+			return New(httpErr.Code, fmt.Sprintf("HTTP_%d", httpErr.Code), detail)
+		}
+	}
+
+	return Internal(err, "Internal server error")
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+func AccessTokenTTL() time.Duration {
+	return getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute)
+}
+
+func RefreshTokenTTL() time.Duration {
+	return getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+}
+
+func EmailVerificationTTL() time.Duration {
+	return getEnvDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour)
+}
+
+// AppBaseURL is the public URL of the API, used to build links in emails.
+func AppBaseURL() string {
+	return strings.TrimRight(getEnv("APP_BASE_URL", "http://localhost:8080"), "/")
+}
+
+func PasswordResetTTL() time.Duration {
+	return getEnvDuration("PASSWORD_RESET_TTL", time.Hour)
+}
+
+// PasswordResetURL is the page the reset email links to. The token is
+// appended as a query parameter.
+func PasswordResetURL() string {
+	return getEnv("PASSWORD_RESET_URL", AppBaseURL()+"/reset-password")
+}
+
+// MFAChallengeTTL bounds the time between the password and second factor
+// steps of a login.
+func MFAChallengeTTL() time.Duration {
+	return getEnvDuration("MFA_CHALLENGE_TTL", 5*time.Minute)
+}
+
+// MFAIssuer is the account issuer shown in authenticator apps.
+func MFAIssuer() string {
+	return getEnv("MFA_ISSUER", "Mini Project")
+}
+
+// AdminEmail is the account promoted to admin at startup, so that a fresh
+// deployment has an admin who can grant roles. Empty disables the bootstrap.
+func AdminEmail() string {
+	return getEnv("ADMIN_EMAIL", "")
+}
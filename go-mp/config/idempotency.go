@@ -0,0 +1,9 @@
+package config
+
+import "time"
+
+// IdempotencyKeyTTL is how long a stored Idempotency-Key response is replayed
+// before the key may be used for a new request.
+func IdempotencyKeyTTL() time.Duration {
+	return getEnvDuration("IDEMPOTENCY_KEY_TTL", 24*time.Hour)
+}
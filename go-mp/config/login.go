@@ -0,0 +1,35 @@
+package config
+
+import (
+	"mini-project/loginguard"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func LoginGuardConfig() loginguard.Config {
+	return loginguard.Config{
+		Window:           getEnvDuration("LOGIN_ATTEMPT_WINDOW", 15*time.Minute),
+		FreeAttempts:     getEnvInt64("LOGIN_FREE_ATTEMPTS", 3),
+		IPFreeAttempts:   getEnvInt64("LOGIN_IP_FREE_ATTEMPTS", 20),
+		BaseDelay:        getEnvDuration("LOGIN_BASE_DELAY", time.Second),
+		MaxDelay:         getEnvDuration("LOGIN_MAX_DELAY", 5*time.Minute),
+		LockoutThreshold: getEnvInt64("LOGIN_LOCKOUT_THRESHOLD", 10),
+		LockoutDuration:  getEnvDuration("LOGIN_LOCKOUT_DURATION", 30*time.Minute),
+	}
+}
+
+// IPExtractor decides where the client IP used for login throttling comes
+// from. Forwarding headers can be forged by clients, so they are only trusted
+// when TRUSTED_PROXY_HEADER says the API runs behind a proxy that sets them.
+func IPExtractor() echo.IPExtractor {
+	switch strings.ToLower(getEnv("TRUSTED_PROXY_HEADER", "")) {
+	case "x-forwarded-for":
+		return echo.ExtractIPFromXFFHeader()
+	case "x-real-ip":
+		return echo.ExtractIPFromRealIPHeader()
+	default:
+		return echo.ExtractIPDirect()
+	}
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"mini-project/mailer"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+// InitMailer builds the mailer selected by MAILER_BACKEND: "smtp" (default),
+// "file" for a local maildir, or "memory".
+func InitMailer() mailer.Mailer {
+	from := getEnv("MAIL_FROM", "tim@part.com")
+
+	switch backend := getEnv("MAILER_BACKEND", "smtp"); backend {
+	case "smtp":
+		host := os.Getenv("SMTP_HOST")
+		if host == "" {
+			logrus.Fatal("SMTP_HOST must be set when MAILER_BACKEND is smtp")
+		}
+
+		return mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     host,
+			Port:     int(getEnvInt64("SMTP_PORT", 587)),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		})
+	case "file":
+		m, err := mailer.NewFileMailer(getEnv("MAIL_DIR", "tmp/mail"), from)
+		if err != nil {
+			logrus.Fatalf("Error creating mail directory: %v", err)
+		}
+		return m
+	case "memory":
+		return mailer.NewMemoryMailer()
+	default:
+		logrus.Fatalf("Unknown MAILER_BACKEND %q", backend)
+		return nil
+	}
+}
@@ -0,0 +1,21 @@
+package config
+
+import (
+	"mini-project/notification"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+// InitTemplates returns the notification renderer. Templates in
+// EMAIL_TEMPLATE_DIR, laid out as <locale>/<name>.<part>.tmpl, override the
+// embedded ones; overrides that do not parse are reported here and replaced
+// by the embedded templates when rendering.
+func InitTemplates() *notification.Renderer {
+	renderer := notification.NewRenderer(os.Getenv("EMAIL_TEMPLATE_DIR"))
+	for _, err := range renderer.CheckOverrides() {
+		logrus.Warnf("Ignoring broken email template override %v", err)
+	}
+
+	return renderer
+}
@@ -0,0 +1,17 @@
+package config
+
+import (
+	"mini-project/outbox"
+	"time"
+)
+
+func OutboxWorkerConfig() outbox.WorkerConfig {
+	return outbox.WorkerConfig{
+		PollInterval: getEnvDuration("OUTBOX_POLL_INTERVAL", 5*time.Second),
+		BatchSize:    int(getEnvInt64("OUTBOX_BATCH_SIZE", 20)),
+		MaxAttempts:  int(getEnvInt64("OUTBOX_MAX_ATTEMPTS", 8)),
+		BaseBackoff:  getEnvDuration("OUTBOX_BASE_BACKOFF", 30*time.Second),
+		MaxBackoff:   getEnvDuration("OUTBOX_MAX_BACKOFF", time.Hour),
+		Lease:        getEnvDuration("OUTBOX_LEASE", 5*time.Minute),
+	}
+}
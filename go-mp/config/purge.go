@@ -0,0 +1,15 @@
+package config
+
+import (
+	"mini-project/purge"
+	"time"
+)
+
+func PurgeJobConfig() purge.Config {
+	return purge.Config{
+		Interval:              getEnvDuration("PURGE_INTERVAL", 24*time.Hour),
+		Retention:             getEnvDuration("SOFT_DELETE_RETENTION", 90*24*time.Hour),
+		LoginAttemptRetention: getEnvDuration("LOGIN_ATTEMPT_RETENTION", 30*24*time.Hour),
+		AuditLogRetention:     getEnvDuration("AUDIT_LOG_RETENTION", 365*24*time.Hour),
+	}
+}
@@ -0,0 +1,7 @@
+package config
+
+// LateReturnFeePerDay is the fee, in minor currency units, charged for every
+// started day a rental is returned after its end date. Zero disables the fee.
+func LateReturnFeePerDay() int64 {
+	return getEnvInt64("LATE_RETURN_FEE_PER_DAY", 0)
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"mini-project/token"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+// InitTokenService loads the JWT keys from the PEM files in JWT_KEYS_DIR and
+// signs new tokens with the key named by JWT_SIGNING_KEY_ID. To rotate, add
+// the new key, switch JWT_SIGNING_KEY_ID to it, and remove the old file once
+// tokens signed with it have expired. Without JWT_KEYS_DIR startup fails,
+// unless JWT_EPHEMERAL_KEY=true asks for a key generated at startup; every
+// token is invalidated by a restart then, so that only suits local
+// development.
+func InitTokenService() *token.Service {
+	dir := os.Getenv("JWT_KEYS_DIR")
+	if dir == "" {
+		if !getEnvBool("JWT_EPHEMERAL_KEY", false) {
+			logrus.Fatal("JWT_KEYS_DIR is not set; set it, or set JWT_EPHEMERAL_KEY=true for local development")
+		}
+		logrus.Warn("JWT_KEYS_DIR is not set; signing tokens with an ephemeral key")
+
+		key, err := token.GenerateEd25519Key("ephemeral")
+		if err != nil {
+			logrus.Fatalf("Error generating JWT key: %v", err)
+		}
+
+		service, err := token.NewService(key.ID, key)
+		if err != nil {
+			logrus.Fatalf("Error creating token service: %v", err)
+		}
+		return service
+	}
+
+	keys, err := token.LoadKeys(dir)
+	if err != nil {
+		logrus.Fatalf("Error loading JWT keys: %v", err)
+	}
+
+	service, err := token.NewService(os.Getenv("JWT_SIGNING_KEY_ID"), keys...)
+	if err != nil {
+		logrus.Fatalf("Error creating token service: %v", err)
+	}
+
+	return service
+}
@@ -0,0 +1,11 @@
+package config
+
+import "mini-project/validation"
+
+func PasswordPolicy() validation.PasswordPolicy {
+	return validation.PasswordPolicy{
+		MinLength:      int(getEnvInt64("PASSWORD_MIN_LENGTH", 8)),
+		MaxLength:      72,
+		RejectBreached: getEnv("PASSWORD_REJECT_BREACHED", "true") == "true",
+	}
+}
@@ -23,35 +23,44 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/equipment": {
+        "/.well-known/jwks.json": {
             "get": {
-                "description": "Retrieve a list of all available equipment",
+                "description": "Public keys for verifying the API's JWTs, identified by the kid header of each token",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get All Equipment",
-                "operationId": "get-all-equipment",
+                "summary": "JSON Web Key Set",
+                "operationId": "get-jwks",
+                "responses": {
+                    "200": {
+                        "description": "Verification keys",
+                        "schema": {
+                            "$ref": "#/definitions/token.JWKSet"
+                        }
+                    }
+                }
+            }
+        },
+        "/account/unlock": {
+            "get": {
+                "description": "Lift a login lockout early with the token from the account locked email",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Unlock Account",
+                "operationId": "unlock-account",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "JWT authorization token",
-                        "name": "authorization",
-                        "in": "header",
+                        "description": "Account unlock token",
+                        "name": "token",
+                        "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of equipment",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.Equipment"
-                            }
-                        }
-                    },
-                    "401": {
-                        "description": "JWT token missing or invalid",
+                        "description": "Account unlocked successfully",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -59,27 +68,29 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "400": {
+                        "description": "Invalid or expired unlock token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
                     "500": {
-                        "description": "Failed to retrieve equipment",
+                        "description": "Failed to unlock account",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Create a new equipment item",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/admin/audit-logs": {
+            "get": {
+                "description": "List security audit entries, newest first by default, one page at a time (admin only). Pages are fetched by passing the next_cursor of the previous page, with the same sort, as cursor; the next page URL is also sent in a Link header.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Create Equipment",
-                "operationId": "create-equipment",
+                "summary": "List Audit Log",
+                "operationId": "list-audit-logs",
                 "parameters": [
                     {
                         "type": "string",
@@ -89,54 +100,131 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Equipment details",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.CreateEquipmentRequestBody"
-                        }
+                        "type": "string",
+                        "description": "Only entries of this event",
+                        "name": "event",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only entries of this user",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "audit_log_id",
+                            "-audit_log_id",
+                            "created_at",
+                            "-created_at"
+                        ],
+                        "type": "string",
+                        "default": "-audit_log_id",
+                        "description": "Column to sort by, prefixed with - for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Count the matches across all pages",
+                        "name": "include_total",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Equipment created successfully",
+                        "description": "One page of audit log entries",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.AuditLogPage"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid query parameter",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "500": {
-                        "description": "Failed to create equipment",
+                        "description": "Failed to retrieve audit log",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/mfa-policies": {
+            "get": {
+                "description": "List the roles and whether they require two-factor authentication (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List MFA Policies",
+                "operationId": "get-mfa-policies",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "MFA policies retrieved successfully",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve MFA policies",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     }
                 }
             }
         },
-        "/equipment/{id}": {
+        "/admin/mfa-policies/{role}": {
             "put": {
-                "description": "Update an existing equipment item by ID",
+                "description": "Require or stop requiring two-factor authentication for a role (admin only). Users of the role without an MFA session are refused on role-protected endpoints until they enable MFA and sign in again.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Update Equipment",
-                "operationId": "update-equipment",
+                "summary": "Update MFA Policy",
+                "operationId": "update-mfa-policy",
                 "parameters": [
                     {
                         "type": "string",
@@ -147,68 +235,64 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Equipment ID",
-                        "name": "id",
+                        "description": "Role",
+                        "name": "role",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated equipment details",
+                        "description": "Policy",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.UpdateEquipmentRequestBody"
+                            "$ref": "#/definitions/model.UpdateMFAPolicyRequestBody"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Equipment updated successfully",
+                        "description": "MFA policy updated successfully",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid request body\" \"Invalid role",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
-                    "404": {
-                        "description": "Equipment not found",
+                    "403": {
+                        "description": "Admin role required",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed, with field errors",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "500": {
-                        "description": "Failed to update equipment",
+                        "description": "Failed to update MFA policy",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete an existing equipment item by ID",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/admin/outbox": {
+            "get": {
+                "description": "List notification emails in the outbox by delivery status, newest first by default, one page at a time (admin only). Message bodies are never listed. Pages are fetched by passing the next_cursor of the previous page, with the same sort, as cursor; the next page URL is also sent in a Link header.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Delete Equipment",
-                "operationId": "delete-equipment",
+                "summary": "List Outbox Messages",
+                "operationId": "list-outbox-messages",
                 "parameters": [
                     {
                         "type": "string",
@@ -219,134 +303,168 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Equipment ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "default": "dead",
+                        "description": "Delivery status: pending, sent or dead",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "outbox_message_id",
+                            "-outbox_message_id",
+                            "next_attempt_at",
+                            "-next_attempt_at",
+                            "created_at",
+                            "-created_at"
+                        ],
+                        "type": "string",
+                        "default": "-outbox_message_id",
+                        "description": "Column to sort by, prefixed with - for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Count the matches across all pages",
+                        "name": "include_total",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Equipment deleted successfully",
+                        "description": "One page of outbox messages",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/model.OutboxMessagePage"
                         }
                     },
-                    "404": {
-                        "description": "Equipment not found",
+                    "400": {
+                        "description": "Invalid status or pagination parameter",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "500": {
-                        "description": "Failed to delete equipment",
+                        "description": "Failed to retrieve outbox messages",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     }
                 }
             }
         },
-        "/login": {
+        "/admin/outbox/{id}/retry": {
             "post": {
-                "description": "Login with the provided email and password to obtain an authentication token",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Queue a dead outbox message for delivery again with a fresh retry budget (admin only)",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Login",
-                "operationId": "login-user",
+                "summary": "Re-drive Outbox Message",
+                "operationId": "redrive-outbox-message",
                 "parameters": [
                     {
-                        "description": "User login request body",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.RegisterRequestBody"
-                        }
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Outbox message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Login successful",
+                        "description": "Outbox message queued for delivery",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid outbox message ID",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
-                    "401": {
-                        "description": "Invalid email or password",
+                    "403": {
+                        "description": "Admin role required",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Outbox message not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Only dead messages can be re-driven",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "500": {
-                        "description": "Failed to generate JWT token",
+                        "description": "Failed to re-drive outbox message",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     }
                 }
             }
         },
-        "/register": {
+        "/admin/users/{id}/sessions/revoke": {
             "post": {
-                "description": "Register a new user with the provided email and password",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Revoke every session of a user, logging them out everywhere (admin only)",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Register a new user",
-                "operationId": "register-user",
+                "summary": "Revoke User Sessions",
+                "operationId": "revoke-user-sessions",
                 "parameters": [
                     {
-                        "description": "User registration request body",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.RegisterRequestBody"
-                        }
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User registered successfully",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid request body",
+                        "description": "User sessions revoked successfully",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -354,29 +472,41 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
                     "500": {
-                        "description": "Failed to hash password\" \"Failed to create user\" \"Failed to send registration email",
+                        "description": "Failed to revoke user sessions",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     }
                 }
             }
         },
-        "/rental": {
+        "/equipment": {
             "get": {
-                "description": "Get a list of all rental history records",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "List equipment one page at a time. Filters combine with AND. Pages are fetched by passing the next_cursor of the previous page, with the same sort, as cursor; the next page URL is also sent in a Link header.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get All Rental History",
-                "operationId": "get-all-rental-history",
+                "summary": "List Equipment",
+                "operationId": "get-all-equipment",
                 "parameters": [
                     {
                         "type": "string",
@@ -384,39 +514,127 @@ const docTemplate = `{
                         "name": "authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only equipment of this category",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only equipment that is (true) or is not (false) available",
+                        "name": "available",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum rental costs in cents, inclusive",
+                        "name": "min_cost",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum rental costs in cents, inclusive",
+                        "name": "max_cost",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only equipment whose name contains this text, ignoring case",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "equipment_id",
+                            "-equipment_id",
+                            "name",
+                            "-name",
+                            "availability",
+                            "-availability",
+                            "rental_costs",
+                            "-rental_costs",
+                            "category",
+                            "-category"
+                        ],
+                        "type": "string",
+                        "default": "equipment_id",
+                        "description": "Column to sort by, prefixed with - for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Count the matches across all pages",
+                        "name": "include_total",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "List deleted equipment too (admin only)",
+                        "name": "include_deleted",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of rental history records",
+                        "description": "Page of equipment",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.RentalHistory"
-                            }
+                            "$ref": "#/definitions/model.EquipmentPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameter",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "JWT token missing or invalid",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins can include deleted records",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "500": {
-                        "description": "Failed to retrieve rental history",
+                        "description": "Failed to retrieve equipment",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a new rental history record",
+                "description": "Create a new equipment item",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Create Rental History",
-                "operationId": "create-rental-history",
+                "summary": "Create Equipment",
+                "operationId": "create-equipment",
                 "parameters": [
                     {
                         "type": "string",
@@ -426,82 +644,67 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Request body containing rental history information",
+                        "description": "Equipment details",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.CreateRentalHistoryRequestBody"
+                            "$ref": "#/definitions/model.CreateEquipmentRequestBody"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Rental history record created successfully",
+                    "201": {
+                        "description": "Equipment created successfully",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/model.Equipment"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the equipment"
+                            },
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the new equipment"
+                            }
                         }
                     },
                     "400": {
                         "description": "Invalid request body",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "402": {
-                        "description": "Insufficient deposit amount",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
-                    "404": {
-                        "description": "User or equipment not found",
+                    "403": {
+                        "description": "Admin role required",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
-                    "409": {
-                        "description": "Equipment is not available for rent",
+                    "422": {
+                        "description": "Validation failed, with field errors",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "500": {
-                        "description": "Failed to create rental history",
+                        "description": "Failed to create equipment",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     }
                 }
             }
         },
-        "/rental/{id}": {
-            "put": {
-                "description": "Update an existing rental history record",
-                "consumes": [
-                    "application/json"
-                ],
+        "/equipment/{id}": {
+            "get": {
+                "description": "Get an equipment item by ID",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Update Rental History",
-                "operationId": "update-rental-history",
+                "summary": "Get Equipment",
+                "operationId": "get-equipment",
                 "parameters": [
                     {
                         "type": "string",
@@ -512,68 +715,82 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "Rental history ID to be updated",
+                        "description": "Equipment ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Request body containing updated rental history information",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.UpdateRentalHistoryRequestBody"
-                        }
+                        "type": "boolean",
+                        "description": "Find the equipment even if it was deleted (admin only)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Rental history updated successfully",
+                        "description": "Equipment",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/model.Equipment"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the equipment"
+                            }
                         }
                     },
+                    "304": {
+                        "description": "Cached copy is still current"
+                    },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid equipment ID",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "JWT token missing or invalid",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins can include deleted records",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "404": {
-                        "description": "Rental history not found",
+                        "description": "Equipment not found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "500": {
-                        "description": "Failed to update rental history",
+                        "description": "Failed to retrieve equipment",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     }
                 }
             },
-            "delete": {
-                "description": "Delete a rental history record by ID",
+            "put": {
+                "description": "Replace an existing equipment item by ID. Every field is set from the request; use PATCH to change only some of them.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Delete Rental History",
-                "operationId": "delete-rental-history",
+                "summary": "Update Equipment",
+                "operationId": "update-equipment",
                 "parameters": [
                     {
                         "type": "string",
@@ -584,54 +801,96 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "Rental history ID to be deleted",
+                        "description": "Equipment ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being changed",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated equipment details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateEquipmentRequestBody"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Rental history deleted successfully",
+                        "description": "Equipment updated successfully",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "additionalProperties": true
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the equipment"
                             }
                         }
                     },
+                    "400": {
+                        "description": "Invalid equipment ID or request body",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
                     "404": {
-                        "description": "Rental history not found",
+                        "description": "Equipment not found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Equipment changed since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed, with field errors",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header missing",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "500": {
-                        "description": "Failed to delete rental history",
+                        "description": "Failed to update equipment",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     }
                 }
-            }
-        },
-        "/top-up": {
-            "post": {
-                "description": "Deposit a specified amount into the user's account balance",
+            },
+            "delete": {
+                "description": "Delete an equipment item by ID. The item is hidden but kept, with its rental history, until the purge job removes it; it can be restored until then. Equipment with active rentals cannot be deleted.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Top-Up User Account",
-                "operationId": "top-up-user",
+                "summary": "Delete Equipment",
+                "operationId": "delete-equipment",
                 "parameters": [
                     {
                         "type": "string",
@@ -641,43 +900,2185 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Amount to deposit",
-                        "name": "deposit_amount",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.TopUpRequestBody"
-                        }
+                        "type": "integer",
+                        "description": "Equipment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Top-up successful",
+                        "description": "Equipment deleted successfully",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid equipment ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Equipment not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Equipment has active rentals",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete equipment",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Change some fields of an existing equipment item with a JSON Merge Patch (RFC 7396). Fields missing from the patch keep their values; the result must still be a valid equipment item.",
+                "consumes": [
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Patch Equipment",
+                "operationId": "patch-equipment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Equipment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being changed",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateEquipmentRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Equipment updated successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the equipment"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid equipment ID or merge patch",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Equipment not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Equipment changed since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type is not application/merge-patch+json",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed, with field errors",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header missing",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update equipment",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/equipment/{id}/availability": {
+            "get": {
+                "description": "Check whether an equipment item can be booked between two dates: it must be in service (availability) and not booked for any part of the range",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Check Equipment Availability",
+                "operationId": "get-equipment-availability",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Equipment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the range (RFC 3339 or YYYY-MM-DD)",
+                        "name": "start",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the range (RFC 3339 or YYYY-MM-DD)",
+                        "name": "end",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Availability of the equipment",
+                        "schema": {
+                            "$ref": "#/definitions/model.EquipmentAvailability"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid equipment ID or date range",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "JWT token missing or invalid",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Equipment not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to check equipment availability",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/equipment/{id}/rentals": {
+            "get": {
+                "description": "List who rented an equipment item and when, ordered by start date by default (admin only). Each rental embeds a summary of its user and equipment. Paginated like the rental history listing.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Equipment Rental Timeline",
+                "operationId": "get-equipment-rentals",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Equipment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "requested,confirmed",
+                        "description": "Only rentals in these statuses, comma separated",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only rentals ending after this time (RFC 3339 or YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only rentals starting before this time (RFC 3339 or YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "rental_history_id",
+                            "-rental_history_id",
+                            "start_date",
+                            "-start_date",
+                            "end_date",
+                            "-end_date",
+                            "rental_status",
+                            "-rental_status"
+                        ],
+                        "type": "string",
+                        "default": "start_date",
+                        "description": "Column to sort by, prefixed with - for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Count the matches across all pages",
+                        "name": "include_total",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "List deleted rentals too (admin only)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of the equipment's rentals",
+                        "schema": {
+                            "$ref": "#/definitions/model.RentalHistoryPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid equipment ID or query parameter",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Equipment not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve rental history",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/equipment/{id}/restore": {
+            "post": {
+                "description": "Undo the deletion of an equipment item (admin only). Restoring equipment that is not deleted changes nothing.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Restore Equipment",
+                "operationId": "restore-equipment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Equipment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Equipment restored successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the equipment"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid equipment ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Equipment not found, or already purged",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to restore equipment",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Login with the provided email and password to obtain a short-lived access token and a refresh token. Repeated failures from the same email or IP are answered with 429 and a Retry-After header, and too many lock the email for a while. Users with two-factor authentication enabled instead receive an mfa_token to exchange at /login/mfa.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Login",
+                "operationId": "login-user",
+                "parameters": [
+                    {
+                        "description": "User login request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.LoginRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login successful\" \"Two-factor authentication required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid email or password",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed, with field errors",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed login attempts",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to generate JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/login/mfa": {
+            "post": {
+                "description": "Exchange the mfa_token returned by /login, together with an authenticator code or an unused recovery code, for an access token and a refresh token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Complete MFA Login",
+                "operationId": "login-mfa",
+                "parameters": [
+                    {
+                        "description": "MFA challenge and code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MFALoginRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login successful",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired MFA challenge\" \"Invalid authentication code",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed, with field errors",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed login attempts",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to generate JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/logout": {
+            "post": {
+                "description": "Revoke the current session, invalidating its access and refresh tokens",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Logout",
+                "operationId": "logout",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Logged out successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "JWT token missing or invalid",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to log out",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/mfa/confirm": {
+            "post": {
+                "description": "Enable two-factor authentication by submitting a code from the authenticator app. The response lists single-use recovery codes, which are only shown once. Sign in again to obtain a session that satisfies MFA policies.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Confirm MFA Enrollment",
+                "operationId": "confirm-mfa",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Code from the authenticator app",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ConfirmMFARequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Two-factor authentication enabled",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body\" \"Invalid authentication code",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "JWT token missing or invalid",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Two-factor authentication is already enabled\" \"Start two-factor enrollment first",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed, with field errors",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to enable two-factor authentication",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/mfa/disable": {
+            "post": {
+                "description": "Turn off two-factor authentication for the current user. Requires a current authenticator or recovery code, and is refused while the user's role requires MFA.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Disable MFA",
+                "operationId": "disable-mfa",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Authenticator or recovery code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MFACodeRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Two-factor authentication disabled",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body\" \"Invalid authentication code",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "JWT token missing or invalid",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Two-factor authentication is not enabled\" \"Two-factor authentication is required for your role",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed, with field errors",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to disable two-factor authentication",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/mfa/enroll": {
+            "post": {
+                "description": "Generate a new TOTP secret for the current user. Scan the provisioning URI as a QR code with an authenticator app, then confirm it with /mfa/confirm.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Start MFA Enrollment",
+                "operationId": "enroll-mfa",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Scan the provisioning URI with your authenticator app",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "JWT token missing or invalid",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Two-factor authentication is already enabled",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to start two-factor enrollment",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/password/forgot": {
+            "post": {
+                "description": "Email a single-use password reset link. The response is the same whether or not the email belongs to an account.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Forgot Password",
+                "operationId": "forgot-password",
+                "parameters": [
+                    {
+                        "description": "Account email",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ForgotPasswordRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "If the email is registered, a reset link has been sent",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed, with field errors",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to process password reset request",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/password/reset": {
+            "post": {
+                "description": "Set a new password with a token from the reset email. The token can be used once, every existing session of the user is revoked and any login lockout is lifted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Reset Password",
+                "operationId": "reset-password",
+                "parameters": [
+                    {
+                        "description": "Reset token and new password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ResetPasswordRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password reset successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body\" \"Invalid or expired reset token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed, with field errors",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to reset password",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Register a new user with the provided email and password. Emails are case-insensitive and must be unique, and the password must satisfy the password policy. A link to confirm the email address is sent to the user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Register a new user",
+                "operationId": "register-user",
+                "parameters": [
+                    {
+                        "description": "User registration request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.RegisterRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User registered successfully",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Email is already registered",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed, with field errors",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to hash password\" \"Failed to create user",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/rental": {
+            "get": {
+                "description": "List the rentals of the authenticated user, or of every user for admins, one page at a time. Each rental embeds a summary of its user and equipment. Filters combine with AND. Pages are fetched by passing the next_cursor of the previous page, with the same sort, as cursor; the next page URL is also sent in a Link header.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List Rental History",
+                "operationId": "get-all-rental-history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only rentals of this user (admin only; other users always see their own)",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only rentals of this equipment",
+                        "name": "equipment_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "requested,confirmed",
+                        "description": "Only rentals in these statuses, comma separated",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only rentals ending after this time (RFC 3339 or YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only rentals starting before this time (RFC 3339 or YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "rental_history_id",
+                            "-rental_history_id",
+                            "start_date",
+                            "-start_date",
+                            "end_date",
+                            "-end_date",
+                            "rental_status",
+                            "-rental_status"
+                        ],
+                        "type": "string",
+                        "default": "rental_history_id",
+                        "description": "Column to sort by, prefixed with - for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Count the matches across all pages",
+                        "name": "include_total",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "List deleted rentals too (admin only)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of rental history records",
+                        "schema": {
+                            "$ref": "#/definitions/model.RentalHistoryPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameter",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "JWT token missing or invalid",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Only admins can include deleted records",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve rental history",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Request a rental for the authenticated user, holding the rental costs from their deposit until the rental is settled",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create Rental History",
+                "operationId": "create-rental-history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Request body containing rental history information",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateRentalHistoryRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Rental history record created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/model.RentalHistory"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the rental history record"
+                            },
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the new rental history record"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "402": {
+                        "description": "Insufficient deposit amount\" \"Wallet balance is negative",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Email address not verified",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User or equipment not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Equipment is already booked for the requested dates or out of service\" \"Idempotency-Key reused with a different request or still in progress",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed, with field errors, including a start date in the past",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create rental history",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/rental/{id}": {
+            "get": {
+                "description": "Get a rental history record by ID, with summaries of its user and equipment. Users other than admins can only get their own rentals.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get Rental History",
+                "operationId": "get-rental-history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rental history ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Find the rental even if it was deleted (admin only)",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rental history record",
+                        "schema": {
+                            "$ref": "#/definitions/model.RentalHistory"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the rental history record"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Cached copy is still current"
+                    },
+                    "400": {
+                        "description": "Invalid rental history ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "JWT token missing or invalid",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Rental belongs to another user, or only admins can include deleted records",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Rental history not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve rental history",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the dates of a requested or confirmed rental history record. user_id and equipment_id must match the record. New dates re-price the deposit hold at the equipment's current rental costs, holding or refunding the difference.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Update Rental History",
+                "operationId": "update-rental-history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rental history ID to be updated",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being changed",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body containing updated rental history information",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateRentalHistoryRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rental history updated successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the rental history record"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid rental history ID or request body",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "402": {
+                        "description": "Insufficient deposit for the re-priced hold\" \"Wallet balance is negative",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Rental history or equipment not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Equipment is already booked for the requested dates or out of service, the rental is past confirmed, or the user or equipment was changed",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Rental history changed since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed, with field errors",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header missing",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update rental history",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a cancelled or returned rental history record by ID. The record is hidden but kept until the purge job removes it, and can be restored until then. Active rentals still hold a deposit and must be cancelled or returned first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete Rental History",
+                "operationId": "delete-rental-history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rental history ID to be deleted",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rental history deleted successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid rental history ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Rental history not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Rental is still active",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete rental history",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Change the dates of a requested or confirmed rental history record with a JSON Merge Patch (RFC 7396). Fields missing from the patch keep their values; user_id and equipment_id cannot change. New dates re-price the deposit hold like a full update.",
+                "consumes": [
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Patch Rental History",
+                "operationId": "patch-rental-history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rental history ID to be updated",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being changed",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateRentalHistoryRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rental history updated successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the rental history record"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid rental history ID or merge patch",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "402": {
+                        "description": "Insufficient deposit for the re-priced hold\" \"Wallet balance is negative",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Rental history or equipment not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Equipment is already booked for the requested dates or out of service, the rental is past confirmed, or the user or equipment was changed",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Rental history changed since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type is not application/merge-patch+json",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed, with field errors",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header missing",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update rental history",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/rental/{id}/cancel": {
+            "post": {
+                "description": "Cancel a requested or confirmed rental and refund the deposit hold. Renters may only cancel their own rentals.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Cancel Rental",
+                "operationId": "cancel-rental",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rental history ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rental cancelled successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid rental history ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Rental belongs to another user",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Rental history not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Illegal status transition",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update rental status",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/rental/{id}/checkout": {
+            "post": {
+                "description": "Hand a confirmed rental's equipment over to the renter (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Check Out Rental",
+                "operationId": "checkout-rental",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rental history ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rental checked out successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid rental history ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Rental history not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Illegal status transition",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update rental status",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/rental/{id}/confirm": {
+            "post": {
+                "description": "Confirm a requested rental and email the renter a confirmation (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Confirm Rental",
+                "operationId": "confirm-rental",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rental history ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rental confirmed successfully",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid rental history ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Rental history not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Illegal status transition",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update rental status",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/rental/{id}/overdue": {
+            "post": {
+                "description": "Flag a checked out rental that has passed its end date and email the renter a reminder (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Mark Rental Overdue",
+                "operationId": "overdue-rental",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rental history ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rental marked as overdue",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid rental history ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Rental history not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Illegal status transition, or the end date has not passed",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update rental status",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/rental/{id}/restore": {
+            "post": {
+                "description": "Undo the deletion of a rental history record (admin only). An active rental is only restored if its equipment exists and is still free for its dates. Restoring a record that is not deleted changes nothing.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Restore Rental History",
+                "operationId": "restore-rental-history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rental history ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rental history restored successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the rental history record"
                             }
                         }
                     },
+                    "400": {
+                        "description": "Invalid rental history ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Rental history not found, or already purged; or its equipment is deleted",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Equipment is already booked for the rental's dates",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to restore rental history",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/rental/{id}/return": {
+            "post": {
+                "description": "Record the return of a checked out or overdue rental, settling the deposit hold, charging any late return fee and emailing the renter a receipt (admin only). The late fee is charged even if it exceeds the wallet balance; the renter then cannot book again until a top-up clears the debt.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Return Rental",
+                "operationId": "return-rental",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rental history ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rental returned successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid rental history ID",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Rental history not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Illegal status transition",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update rental status",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/token/refresh": {
+            "post": {
+                "description": "Exchange a refresh token for a new access token and a new refresh token. Each refresh token can be used once; reusing one revokes the whole session.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Refresh Tokens",
+                "operationId": "refresh-token",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.RefreshTokenRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "New token pair",
+                        "schema": {
+                            "$ref": "#/definitions/model.TokenPair"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired refresh token\" \"Refresh token reuse detected",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed, with field errors",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to refresh token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/top-up": {
+            "post": {
+                "description": "Deposit a specified amount, in cents, into the user's wallet. A top-up first settles any negative balance left by late return fees.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Top-Up User Account",
+                "operationId": "top-up-user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Amount to deposit",
+                        "name": "deposit_amount",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TopUpRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Top-up successful",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body\" \"Deposit amount must be positive",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
                     "401": {
                         "description": "JWT token missing or invalid",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Email address not verified",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency-Key reused with a different request or still in progress",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed, with field errors",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to perform top-up",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/role": {
+            "put": {
+                "description": "Change the role of a user account and sign it out of every session, so the new role applies from its next login (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Update User Role",
+                "operationId": "update-user-role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateUserRoleRequestBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User role updated successfully",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID or request body",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "JWT token missing or invalid",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "404": {
                         "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed, with field errors",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update user role",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/verify-email": {
+            "get": {
+                "description": "Confirm ownership of an email address with the token from the verification email",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Verify Email",
+                "operationId": "verify-email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Email verification token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Email verified successfully",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -685,81 +3086,553 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "400": {
+                        "description": "Invalid or expired verification token",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
                     "500": {
-                        "description": "Failed to perform top-up\" \"Failed to send top-up email",
+                        "description": "Failed to verify email",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/verify-email/resend": {
+            "post": {
+                "description": "Send a new email verification link to the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Resend Verification Email",
+                "operationId": "resend-verification-email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Verification email sent",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
+                    },
+                    "401": {
+                        "description": "JWT token missing or invalid",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Email is already verified",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to send verification email",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallet": {
+            "get": {
+                "description": "Get the authenticated user's wallet balance and the amount currently held for rentals, in cents. Late return fees are charged even when they exceed the balance, so the balance can be negative; outstanding is then the debt, and new rentals are refused until top-ups have cleared it.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get Wallet Balance",
+                "operationId": "get-wallet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Wallet balance",
+                        "schema": {
+                            "$ref": "#/definitions/model.WalletBalance"
+                        }
+                    },
+                    "401": {
+                        "description": "JWT token missing or invalid",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve wallet balance",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallet/transactions": {
+            "get": {
+                "description": "List every top-up, rental hold, charge, refund and fee on the authenticated user's wallet, newest first. Amounts are in cents.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get Wallet Transactions",
+                "operationId": "get-wallet-transactions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT authorization token",
+                        "name": "authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Wallet transactions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.WalletTransaction"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "JWT token missing or invalid",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve wallet transactions",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
                     }
                 }
             }
         }
     },
     "definitions": {
+        "apperr.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "EQUIPMENT_NOT_FOUND"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "Equipment not found"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/validation.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/equipment/42"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "trace_id": {
+                    "description": "TraceID matches the X-Request-ID response header and the server logs.",
+                    "type": "string",
+                    "example": "3fJk2n9QbV1c7xYpL0aRzT5uW8eH4mDs"
+                },
+                "type": {
+                    "description": "Type is always about:blank; Code identifies the kind of problem.",
+                    "type": "string",
+                    "example": "about:blank"
+                }
+            }
+        },
+        "model.AuditLog": {
+            "type": "object",
+            "properties": {
+                "auditLogID": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "ipaddress": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.AuditLogPage": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.AuditLog"
+                    }
+                },
+                "next": {
+                    "description": "Next is the URL of the following page.",
+                    "type": "string",
+                    "example": "/admin/audit-logs?cursor=eyJzIjoiLWF1ZGl0X2xvZ19pZCIsInYiOjIwLCJpZCI6MjB9"
+                },
+                "next_cursor": {
+                    "description": "NextCursor fetches the following page; it is omitted on the last page.",
+                    "type": "string",
+                    "example": "eyJzIjoiLWF1ZGl0X2xvZ19pZCIsInYiOjIwLCJpZCI6MjB9"
+                },
+                "total": {
+                    "description": "Total counts every match across all pages, if include_total was set.",
+                    "type": "integer",
+                    "example": 1234
+                }
+            }
+        },
+        "model.BookedDateRange": {
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ConfirmMFARequestBody": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 10
+                }
+            }
+        },
         "model.CreateEquipmentRequestBody": {
             "type": "object",
+            "required": [
+                "category",
+                "name"
+            ],
             "properties": {
                 "availability": {
                     "type": "boolean"
                 },
                 "category": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 50
                 },
                 "name": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 100
                 },
                 "rental_costs": {
-                    "type": "number"
+                    "type": "integer"
                 }
             }
         },
         "model.CreateRentalHistoryRequestBody": {
             "type": "object",
+            "required": [
+                "end_date",
+                "equipment_id",
+                "start_date"
+            ],
             "properties": {
+                "end_date": {
+                    "type": "string"
+                },
                 "equipment_id": {
                     "type": "integer"
                 },
-                "rental_date": {
+                "start_date": {
                     "type": "string"
+                }
+            }
+        },
+        "model.Equipment": {
+            "type": "object",
+            "properties": {
+                "availability": {
+                    "type": "boolean"
                 },
-                "rental_status": {
+                "category": {
                     "type": "string"
                 },
-                "return_date": {
+                "deletedAt": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "equipmentID": {
+                    "type": "integer"
+                },
+                "name": {
                     "type": "string"
                 },
-                "user_id": {
+                "rentalCosts": {
+                    "description": "in cents",
+                    "type": "integer"
+                },
+                "version": {
                     "type": "integer"
                 }
             }
         },
-        "model.Equipment": {
+        "model.EquipmentAvailability": {
             "type": "object",
             "properties": {
-                "availability": {
+                "available": {
                     "type": "boolean"
                 },
+                "booked_slots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.BookedDateRange"
+                    }
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "equipment_id": {
+                    "type": "integer"
+                },
+                "start_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.EquipmentPage": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Equipment"
+                    }
+                },
+                "next": {
+                    "description": "Next is the URL of the following page.",
+                    "type": "string",
+                    "example": "/equipment?cursor=eyJzIjoiIiwidiI6MjAsImlkIjoyMH0"
+                },
+                "next_cursor": {
+                    "description": "NextCursor fetches the following page; it is omitted on the last page.",
+                    "type": "string",
+                    "example": "eyJzIjoiIiwidiI6MjAsImlkIjoyMH0"
+                },
+                "total": {
+                    "description": "Total counts every match across all pages, if include_total was set.",
+                    "type": "integer",
+                    "example": 1234
+                }
+            }
+        },
+        "model.EquipmentSummary": {
+            "type": "object",
+            "properties": {
                 "category": {
                     "type": "string"
                 },
-                "equipmentID": {
+                "equipment_id": {
                     "type": "integer"
                 },
                 "name": {
                     "type": "string"
+                }
+            }
+        },
+        "model.ForgotPasswordRequestBody": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.LoginRequestBody": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
                 },
-                "rentalCosts": {
-                    "type": "number"
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.MFACodeRequestBody": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 10
+                },
+                "recovery_code": {
+                    "type": "string",
+                    "maxLength": 32
+                }
+            }
+        },
+        "model.MFALoginRequestBody": {
+            "type": "object",
+            "required": [
+                "mfa_token"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 10
+                },
+                "mfa_token": {
+                    "type": "string"
+                },
+                "recovery_code": {
+                    "type": "string",
+                    "maxLength": 32
+                }
+            }
+        },
+        "model.OutboxMessage": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "lockedUntil": {
+                    "description": "LockedUntil is the end of the lease a worker took when it claimed the\nmessage. Other workers leave the message alone until it expires.",
+                    "type": "string"
+                },
+                "nextAttemptAt": {
+                    "type": "string"
+                },
+                "outboxMessageID": {
+                    "type": "integer"
+                },
+                "recipient": {
+                    "type": "string"
+                },
+                "sentAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.OutboxMessagePage": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.OutboxMessage"
+                    }
+                },
+                "next": {
+                    "description": "Next is the URL of the following page.",
+                    "type": "string",
+                    "example": "/admin/outbox?cursor=eyJzIjoiLW91dGJveF9tZXNzYWdlX2lkIiwidiI6MjAsImlkIjoyMH0"
+                },
+                "next_cursor": {
+                    "description": "NextCursor fetches the following page; it is omitted on the last page.",
+                    "type": "string",
+                    "example": "eyJzIjoiLW91dGJveF9tZXNzYWdlX2lkIiwidiI6MjAsImlkIjoyMH0"
+                },
+                "total": {
+                    "description": "Total counts every match across all pages, if include_total was set.",
+                    "type": "integer",
+                    "example": 1234
+                }
+            }
+        },
+        "model.RefreshTokenRequestBody": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
                 }
             }
         },
         "model.RegisterRequestBody": {
             "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
             "properties": {
                 "email": {
+                    "type": "string",
+                    "maxLength": 254
+                },
+                "locale": {
                     "type": "string"
                 },
                 "password": {
@@ -770,23 +3643,103 @@ const docTemplate = `{
         "model.RentalHistory": {
             "type": "object",
             "properties": {
-                "equipmentID": {
+                "amountCharged": {
+                    "type": "integer"
+                },
+                "checkedOutAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "depositHold": {
                     "type": "integer"
                 },
-                "rentalDate": {
+                "endDate": {
                     "type": "string"
                 },
+                "equipment": {
+                    "$ref": "#/definitions/model.EquipmentSummary"
+                },
+                "equipmentID": {
+                    "type": "integer"
+                },
                 "rentalHistoryID": {
                     "type": "integer"
                 },
                 "rentalStatus": {
                     "type": "string"
                 },
-                "returnDate": {
+                "returnedAt": {
+                    "type": "string"
+                },
+                "startDate": {
                     "type": "string"
                 },
+                "user": {
+                    "$ref": "#/definitions/model.UserSummary"
+                },
                 "userID": {
                     "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.RentalHistoryPage": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RentalHistory"
+                    }
+                },
+                "next": {
+                    "description": "Next is the URL of the following page.",
+                    "type": "string",
+                    "example": "/rental?cursor=eyJzIjoiIiwidiI6MjAsImlkIjoyMH0"
+                },
+                "next_cursor": {
+                    "description": "NextCursor fetches the following page; it is omitted on the last page.",
+                    "type": "string",
+                    "example": "eyJzIjoiIiwidiI6MjAsImlkIjoyMH0"
+                },
+                "total": {
+                    "description": "Total counts every match across all pages, if include_total was set.",
+                    "type": "integer",
+                    "example": 1234
+                }
+            }
+        },
+        "model.ResetPasswordRequestBody": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.TokenPair": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "type": "integer"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
                 }
             }
         },
@@ -794,46 +3747,186 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "deposit_amount": {
-                    "type": "number"
+                    "type": "integer"
                 }
             }
         },
         "model.UpdateEquipmentRequestBody": {
             "type": "object",
+            "required": [
+                "category",
+                "name"
+            ],
             "properties": {
                 "availability": {
                     "type": "boolean"
                 },
                 "category": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 50
                 },
                 "name": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 100
                 },
                 "rental_costs": {
-                    "type": "number"
+                    "type": "integer"
+                }
+            }
+        },
+        "model.UpdateMFAPolicyRequestBody": {
+            "type": "object",
+            "required": [
+                "required"
+            ],
+            "properties": {
+                "required": {
+                    "type": "boolean"
                 }
             }
         },
         "model.UpdateRentalHistoryRequestBody": {
             "type": "object",
+            "required": [
+                "end_date",
+                "equipment_id",
+                "start_date",
+                "user_id"
+            ],
             "properties": {
+                "end_date": {
+                    "type": "string"
+                },
                 "equipment_id": {
                     "type": "integer"
                 },
-                "rental_date": {
+                "start_date": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.UpdateUserRoleRequestBody": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "renter"
+                    ]
+                }
+            }
+        },
+        "model.UserSummary": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.WalletBalance": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "integer"
+                },
+                "held": {
+                    "type": "integer"
+                },
+                "outstanding": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.WalletTransaction": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "balance_after": {
+                    "type": "integer"
+                },
+                "created_at": {
                     "type": "string"
                 },
-                "rental_status": {
+                "description": {
                     "type": "string"
                 },
-                "return_date": {
+                "entry_type": {
                     "type": "string"
                 },
-                "user_id": {
+                "journal_entry_id": {
+                    "type": "integer"
+                },
+                "rental_history_id": {
                     "type": "integer"
                 }
             }
+        },
+        "token.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                }
+            }
+        },
+        "token.JWKSet": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/token.JWK"
+                    }
+                }
+            }
+        },
+        "validation.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"mini-project/apperr"
+	"mini-project/model"
+	"mini-project/repository"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// recordAudit appends an entry to the audit log as part of tx.
+func recordAudit(tx *gorm.DB, c echo.Context, event string, userID *uint, email, detail string) error {
+	return tx.Create(&model.AuditLog{
+		Event:     event,
+		UserID:    userID,
+		Email:     email,
+		IPAddress: c.RealIP(),
+		Detail:    detail,
+	}).Error
+}
+
+// @Summary List Audit Log
+// @Description List security audit entries, newest first by default, one page at a time (admin only). Pages are fetched by passing the next_cursor of the previous page, with the same sort, as cursor; the next page URL is also sent in a Link header.
+// @ID list-audit-logs
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Param event query string false "Only entries of this event"
+// @Param user_id query int false "Only entries of this user"
+// @Param sort query string false "Column to sort by, prefixed with - for descending order" Enums(audit_log_id, -audit_log_id, created_at, -created_at) default(-audit_log_id)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Page size" minimum(1) maximum(100) default(20)
+// @Param include_total query bool false "Count the matches across all pages"
+// @Success 200 {object} model.AuditLogPage "One page of audit log entries"
+// @Failure 400 {object} apperr.Problem "Invalid query parameter"
+// @Failure 403 {object} apperr.Problem "Admin role required"
+// @Failure 500 {object} apperr.Problem "Failed to retrieve audit log"
+// @Router /admin/audit-logs [get]
+func GetAuditLogsHandler(c echo.Context) error {
+	filter := repository.AuditLogFilter{Event: c.QueryParam("event")}
+	var err error
+	if filter.UserID, err = uintParam(c, "user_id"); err != nil {
+		return err
+	}
+
+	req, err := pageRequest(c)
+	if err != nil {
+		return err
+	}
+	if req.Sort == "" {
+		req.Sort = "-audit_log_id"
+	}
+
+	page, err := repository.ListAuditLogs(db, filter, req)
+	if err != nil {
+		return apperr.From(pageError(err), "Failed to retrieve audit log")
+	}
+
+	return c.JSON(http.StatusOK, model.AuditLogPage{
+		Data:       page.Items,
+		NextCursor: page.NextCursor,
+		Next:       nextPageURL(c, page.NextCursor),
+		Total:      page.Total,
+	})
+}
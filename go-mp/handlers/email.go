@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"mini-project/model"
+	"mini-project/notification"
+	"mini-project/outbox"
+
+	"gorm.io/gorm"
+)
+
+var templates *notification.Renderer
+
+func SetTemplates(renderer *notification.Renderer) {
+	templates = renderer
+}
+
+// queueNotification renders the notification in the user's locale and stores
+// it in the outbox as part of tx.
+func queueNotification(tx *gorm.DB, user model.User, name string, data interface{}) error {
+	msg, err := templates.Render(name, user.Locale, user.Email, data)
+	if err != nil {
+		return err
+	}
+
+	return outbox.Enqueue(tx, msg)
+}
@@ -1,7 +1,11 @@
 package handlers
 
 import (
+	"fmt"
+	"mini-project/apperr"
+	"mini-project/helper"
 	"mini-project/model"
+	"mini-project/repository"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
@@ -21,14 +25,21 @@ func SetDB(database *gorm.DB) {
 // @Produce json
 // @Param authorization header string true "JWT authorization token"
 // @Param request body model.CreateEquipmentRequestBody true "Equipment details"
-// @Success 200 {string} string "Equipment created successfully"
-// @Failure 400 {object} map[string]string "Invalid request body"
-// @Failure 500 {object} map[string]string "Failed to create equipment"
+// @Success 201 {object} model.Equipment "Equipment created successfully"
+// @Header 201 {string} Location "URL of the new equipment"
+// @Header 201 {string} ETag "Version of the equipment"
+// @Failure 400 {object} apperr.Problem "Invalid request body"
+// @Failure 403 {object} apperr.Problem "Admin role required"
+// @Failure 422 {object} apperr.Problem "Validation failed, with field errors"
+// @Failure 500 {object} apperr.Problem "Failed to create equipment"
 // @Router /equipment [post]
 func CreateEquipmentHandler(c echo.Context) error {
 	var requestBody model.CreateEquipmentRequestBody
 	if err := c.Bind(&requestBody); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid request body"})
+		return apperr.ErrInvalidRequest
+	}
+	if err := c.Validate(&requestBody); err != nil {
+		return invalidRequest(err)
 	}
 
 	newEquipment := model.Equipment{
@@ -39,98 +50,380 @@ func CreateEquipmentHandler(c echo.Context) error {
 	}
 
 	if err := db.Create(&newEquipment).Error; err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to create equipment"})
+		return apperr.Internal(err, "Failed to create equipment")
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{"message": "Equipment created successfully"})
+	c.Response().Header().Set(echo.HeaderLocation, fmt.Sprintf("/equipment/%d", newEquipment.EquipmentID))
+	setETag(c, newEquipment.Version)
+	return c.JSON(http.StatusCreated, newEquipment)
 }
 
-// @Summary Get All Equipment
-// @Description Retrieve a list of all available equipment
+// @Summary Get Equipment
+// @Description Get an equipment item by ID
+// @ID get-equipment
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Param id path int true "Equipment ID"
+// @Param include_deleted query bool false "Find the equipment even if it was deleted (admin only)"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Success 200 {object} model.Equipment "Equipment"
+// @Header 200 {string} ETag "Version of the equipment"
+// @Success 304 "Cached copy is still current"
+// @Failure 400 {object} apperr.Problem "Invalid equipment ID"
+// @Failure 401 {object} apperr.Problem "JWT token missing or invalid"
+// @Failure 403 {object} apperr.Problem "Only admins can include deleted records"
+// @Failure 404 {object} apperr.Problem "Equipment not found"
+// @Failure 500 {object} apperr.Problem "Failed to retrieve equipment"
+// @Router /equipment/{id} [get]
+func GetEquipmentHandler(c echo.Context) error {
+	equipmentID, err := pathID(c, "id", "Invalid equipment ID")
+	if err != nil {
+		return err
+	}
+
+	include, err := includeDeleted(c)
+	if err != nil {
+		return err
+	}
+
+	equipment, err := repository.FindEquipment(scoped(include), equipmentID)
+	if err != nil {
+		return apperr.From(notFound(err), "Failed to retrieve equipment")
+	}
+
+	if notModified(c, equipment.Version) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	return c.JSON(http.StatusOK, equipment)
+}
+
+// @Summary List Equipment
+// @Description List equipment one page at a time. Filters combine with AND. Pages are fetched by passing the next_cursor of the previous page, with the same sort, as cursor; the next page URL is also sent in a Link header.
 // @ID get-all-equipment
 // @Produce json
 // @Param authorization header string true "JWT authorization token"
-// @Success 200 {array} model.Equipment "List of equipment"
-// @Failure 401 {object} map[string]string "JWT token missing or invalid"
-// @Failure 500 {object} map[string]string "Failed to retrieve equipment"
+// @Param category query string false "Only equipment of this category"
+// @Param available query bool false "Only equipment that is (true) or is not (false) available"
+// @Param min_cost query int false "Minimum rental costs in cents, inclusive"
+// @Param max_cost query int false "Maximum rental costs in cents, inclusive"
+// @Param q query string false "Only equipment whose name contains this text, ignoring case"
+// @Param sort query string false "Column to sort by, prefixed with - for descending order" Enums(equipment_id, -equipment_id, name, -name, availability, -availability, rental_costs, -rental_costs, category, -category) default(equipment_id)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Page size" minimum(1) maximum(100) default(20)
+// @Param include_total query bool false "Count the matches across all pages"
+// @Param include_deleted query bool false "List deleted equipment too (admin only)"
+// @Success 200 {object} model.EquipmentPage "Page of equipment"
+// @Failure 400 {object} apperr.Problem "Invalid query parameter"
+// @Failure 401 {object} apperr.Problem "JWT token missing or invalid"
+// @Failure 403 {object} apperr.Problem "Only admins can include deleted records"
+// @Failure 500 {object} apperr.Problem "Failed to retrieve equipment"
 // @Router /equipment [get]
 func GetAllEquipmentHandler(c echo.Context) error {
-	var equipment []model.Equipment
+	req, err := pageRequest(c)
+	if err != nil {
+		return err
+	}
+
+	filter := repository.EquipmentFilter{
+		Category: c.QueryParam("category"),
+		Search:   c.QueryParam("q"),
+	}
+	if filter.Available, err = boolParam(c, "available"); err != nil {
+		return err
+	}
+	if filter.MinCost, err = int64Param(c, "min_cost", 0); err != nil {
+		return err
+	}
+	if filter.MaxCost, err = int64Param(c, "max_cost", 0); err != nil {
+		return err
+	}
+	if filter.MinCost != nil && filter.MaxCost != nil && *filter.MaxCost < *filter.MinCost {
+		return invalidParameter("max_cost", "must not be less than min_cost")
+	}
+	if filter.IncludeDeleted, err = includeDeleted(c); err != nil {
+		return err
+	}
 
-	if err := db.Find(&equipment).Error; err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to retrieve equipment"})
+	page, err := repository.ListEquipment(db, filter, req)
+	if err != nil {
+		return apperr.From(pageError(err), "Failed to retrieve equipment")
 	}
 
-	return c.JSON(http.StatusOK, equipment)
+	return c.JSON(http.StatusOK, model.EquipmentPage{
+		Data:       page.Items,
+		NextCursor: page.NextCursor,
+		Next:       nextPageURL(c, page.NextCursor),
+		Total:      page.Total,
+	})
+}
+
+// @Summary Check Equipment Availability
+// @Description Check whether an equipment item can be booked between two dates: it must be in service (availability) and not booked for any part of the range
+// @ID get-equipment-availability
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Param id path int true "Equipment ID"
+// @Param start query string true "Start of the range (RFC 3339 or YYYY-MM-DD)"
+// @Param end query string true "End of the range (RFC 3339 or YYYY-MM-DD)"
+// @Success 200 {object} model.EquipmentAvailability "Availability of the equipment"
+// @Failure 400 {object} apperr.Problem "Invalid equipment ID or date range"
+// @Failure 401 {object} apperr.Problem "JWT token missing or invalid"
+// @Failure 404 {object} apperr.Problem "Equipment not found"
+// @Failure 500 {object} apperr.Problem "Failed to check equipment availability"
+// @Router /equipment/{id}/availability [get]
+func GetEquipmentAvailabilityHandler(c echo.Context) error {
+	equipmentID, err := pathID(c, "id", "Invalid equipment ID")
+	if err != nil {
+		return err
+	}
+
+	start, err := helper.ParseDate(c.QueryParam("start"))
+	if err != nil {
+		return apperr.ErrInvalidDateRange
+	}
+
+	end, err := helper.ParseDate(c.QueryParam("end"))
+	if err != nil || !end.After(start) {
+		return apperr.ErrInvalidDateRange
+	}
+
+	equipment, err := repository.FindEquipment(db, equipmentID)
+	if err != nil {
+		return apperr.From(notFound(err), "Failed to check equipment availability")
+	}
+
+	overlapping, err := findOverlappingRentals(db, equipment.EquipmentID, start, end, 0)
+	if err != nil {
+		return apperr.Internal(err, "Failed to check equipment availability")
+	}
+
+	bookedSlots := make([]model.BookedDateRange, 0, len(overlapping))
+	for _, rental := range overlapping {
+		bookedSlots = append(bookedSlots, model.BookedDateRange{
+			StartDate: rental.StartDate,
+			EndDate:   rental.EndDate,
+		})
+	}
+
+	return c.JSON(http.StatusOK, model.EquipmentAvailability{
+		EquipmentID: equipment.EquipmentID,
+		StartDate:   start,
+		EndDate:     end,
+		Available:   equipment.Availability && len(bookedSlots) == 0,
+		BookedSlots: bookedSlots,
+	})
 }
 
 // @Summary Update Equipment
-// @Description Update an existing equipment item by ID
+// @Description Replace an existing equipment item by ID. Every field is set from the request; use PATCH to change only some of them.
 // @ID update-equipment
 // @Accept json
 // @Produce json
 // @Param authorization header string true "JWT authorization token"
-// @Param id path string true "Equipment ID"
+// @Param id path int true "Equipment ID"
+// @Param If-Match header string true "ETag of the version being changed"
 // @Param request body model.UpdateEquipmentRequestBody true "Updated equipment details"
 // @Success 200 {object} map[string]interface{} "Equipment updated successfully"
-// @Failure 400 {object} map[string]string "Invalid request body"
-// @Failure 404 {object} map[string]string "Equipment not found"
-// @Failure 500 {object} map[string]string "Failed to update equipment"
+// @Header 200 {string} ETag "New version of the equipment"
+// @Failure 400 {object} apperr.Problem "Invalid equipment ID or request body"
+// @Failure 403 {object} apperr.Problem "Admin role required"
+// @Failure 404 {object} apperr.Problem "Equipment not found"
+// @Failure 412 {object} apperr.Problem "Equipment changed since it was read"
+// @Failure 422 {object} apperr.Problem "Validation failed, with field errors"
+// @Failure 428 {object} apperr.Problem "If-Match header missing"
+// @Failure 500 {object} apperr.Problem "Failed to update equipment"
 // @Router /equipment/{id} [put]
 func UpdateEquipmentHandler(c echo.Context) error {
+	equipmentID, err := pathID(c, "id", "Invalid equipment ID")
+	if err != nil {
+		return err
+	}
+
 	var requestBody model.UpdateEquipmentRequestBody
 	if err := c.Bind(&requestBody); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid request body"})
+		return apperr.ErrInvalidRequest
+	}
+	if err := c.Validate(&requestBody); err != nil {
+		return invalidRequest(err)
 	}
 
-	equipmentID := c.Param("id")
+	existingEquipment, err := repository.FindEquipment(db, equipmentID)
+	if err != nil {
+		return apperr.From(notFound(err), "Failed to update equipment")
+	}
+	if err := checkIfMatch(c, existingEquipment.Version); err != nil {
+		return err
+	}
+
+	return saveEquipment(c, existingEquipment, requestBody)
+}
 
-	var existingEquipment model.Equipment
-	if err := db.First(&existingEquipment, equipmentID).Error; err != nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"message": "Equipment not found"})
+// @Summary Patch Equipment
+// @Description Change some fields of an existing equipment item with a JSON Merge Patch (RFC 7396). Fields missing from the patch keep their values; the result must still be a valid equipment item.
+// @ID patch-equipment
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Param id path int true "Equipment ID"
+// @Param If-Match header string true "ETag of the version being changed"
+// @Param request body model.UpdateEquipmentRequestBody true "Fields to change"
+// @Success 200 {object} map[string]interface{} "Equipment updated successfully"
+// @Header 200 {string} ETag "New version of the equipment"
+// @Failure 400 {object} apperr.Problem "Invalid equipment ID or merge patch"
+// @Failure 403 {object} apperr.Problem "Admin role required"
+// @Failure 404 {object} apperr.Problem "Equipment not found"
+// @Failure 412 {object} apperr.Problem "Equipment changed since it was read"
+// @Failure 415 {object} apperr.Problem "Content-Type is not application/merge-patch+json"
+// @Failure 422 {object} apperr.Problem "Validation failed, with field errors"
+// @Failure 428 {object} apperr.Problem "If-Match header missing"
+// @Failure 500 {object} apperr.Problem "Failed to update equipment"
+// @Router /equipment/{id} [patch]
+func PatchEquipmentHandler(c echo.Context) error {
+	equipmentID, err := pathID(c, "id", "Invalid equipment ID")
+	if err != nil {
+		return err
 	}
 
-	if requestBody.Name != "" {
-		existingEquipment.Name = requestBody.Name
+	existingEquipment, err := repository.FindEquipment(db, equipmentID)
+	if err != nil {
+		return apperr.From(notFound(err), "Failed to update equipment")
 	}
-	existingEquipment.Availability = requestBody.Availability
-	existingEquipment.RentalCosts = requestBody.RentalCosts
-	if requestBody.Category != "" {
-		existingEquipment.Category = requestBody.Category
+	if err := checkIfMatch(c, existingEquipment.Version); err != nil {
+		return err
 	}
 
-	if err := db.Save(&existingEquipment).Error; err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to update equipment"})
+	requestBody := model.UpdateEquipmentRequestBody{
+		Name:         existingEquipment.Name,
+		Availability: existingEquipment.Availability,
+		RentalCosts:  existingEquipment.RentalCosts,
+		Category:     existingEquipment.Category,
+	}
+	if err := applyMergePatch(c, &requestBody); err != nil {
+		return err
+	}
+
+	return saveEquipment(c, existingEquipment, requestBody)
+}
+
+// saveEquipment writes the fields of requestBody over equipment, provided no
+// other update has changed it since it was read.
+func saveEquipment(c echo.Context, equipment model.Equipment, requestBody model.UpdateEquipmentRequestBody) error {
+	result := db.Model(&model.Equipment{}).
+		Where("equipment_id = ? AND version = ?", equipment.EquipmentID, equipment.Version).
+		Updates(map[string]interface{}{
+			"name":         requestBody.Name,
+			"availability": requestBody.Availability,
+			"rental_costs": requestBody.RentalCosts,
+			"category":     requestBody.Category,
+			"version":      gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return apperr.Internal(result.Error, "Failed to update equipment")
+	}
+	if result.RowsAffected == 0 {
+		return apperr.ErrStaleVersion
 	}
 
+	equipment.Name = requestBody.Name
+	equipment.Availability = requestBody.Availability
+	equipment.RentalCosts = requestBody.RentalCosts
+	equipment.Category = requestBody.Category
+	equipment.Version++
+
+	setETag(c, equipment.Version)
+
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"message":   "Equipment updated successfully",
-		"equipment": existingEquipment,
+		"equipment": equipment,
 	})
 }
 
 // @Summary Delete Equipment
-// @Description Delete an existing equipment item by ID
+// @Description Delete an equipment item by ID. The item is hidden but kept, with its rental history, until the purge job removes it; it can be restored until then. Equipment with active rentals cannot be deleted.
 // @ID delete-equipment
 // @Accept json
 // @Produce json
 // @Param authorization header string true "JWT authorization token"
-// @Param id path string true "Equipment ID"
+// @Param id path int true "Equipment ID"
 // @Success 200 {object} map[string]string "Equipment deleted successfully"
-// @Failure 404 {object} map[string]string "Equipment not found"
-// @Failure 500 {object} map[string]string "Failed to delete equipment"
+// @Failure 400 {object} apperr.Problem "Invalid equipment ID"
+// @Failure 403 {object} apperr.Problem "Admin role required"
+// @Failure 404 {object} apperr.Problem "Equipment not found"
+// @Failure 409 {object} apperr.Problem "Equipment has active rentals"
+// @Failure 500 {object} apperr.Problem "Failed to delete equipment"
 // @Router /equipment/{id} [delete]
 func DeleteEquipmentHandler(c echo.Context) error {
-	equipmentID := c.Param("id")
-
-	var existingEquipment model.Equipment
-	if err := db.First(&existingEquipment, equipmentID).Error; err != nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"message": "Equipment not found"})
+	equipmentID, err := pathID(c, "id", "Invalid equipment ID")
+	if err != nil {
+		return err
 	}
 
-	if err := db.Delete(&existingEquipment).Error; err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to delete equipment"})
+	// Bookings lock the equipment row too, so none can start while the
+	// active rentals are counted.
+	err = db.Transaction(func(tx *gorm.DB) error {
+		existingEquipment, err := repository.FindEquipment(forUpdate(tx), equipmentID)
+		if err != nil {
+			return notFound(err)
+		}
+
+		var active int64
+		err = tx.Model(&model.RentalHistory{}).
+			Where("equipment_id = ? AND rental_status NOT IN ?", equipmentID, model.InactiveRentalStatuses).
+			Count(&active).Error
+		if err != nil {
+			return err
+		}
+		if active > 0 {
+			return apperr.ErrEquipmentInUse.WithDetail(fmt.Sprintf("Equipment has %d active rentals", active))
+		}
+
+		return tx.Delete(&existingEquipment).Error
+	})
+	if err != nil {
+		return apperr.From(err, "Failed to delete equipment")
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{"message": "Equipment deleted successfully"})
-}
\ No newline at end of file
+}
+
+// @Summary Restore Equipment
+// @Description Undo the deletion of an equipment item (admin only). Restoring equipment that is not deleted changes nothing.
+// @ID restore-equipment
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Param id path int true "Equipment ID"
+// @Success 200 {object} map[string]interface{} "Equipment restored successfully"
+// @Header 200 {string} ETag "New version of the equipment"
+// @Failure 400 {object} apperr.Problem "Invalid equipment ID"
+// @Failure 403 {object} apperr.Problem "Admin role required"
+// @Failure 404 {object} apperr.Problem "Equipment not found, or already purged"
+// @Failure 500 {object} apperr.Problem "Failed to restore equipment"
+// @Router /equipment/{id}/restore [post]
+func RestoreEquipmentHandler(c echo.Context) error {
+	equipmentID, err := pathID(c, "id", "Invalid equipment ID")
+	if err != nil {
+		return err
+	}
+
+	err = db.Unscoped().Model(&model.Equipment{}).
+		Where("equipment_id = ? AND deleted_at IS NOT NULL", equipmentID).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+		}).Error
+	if err != nil {
+		return apperr.Internal(err, "Failed to restore equipment")
+	}
+
+	equipment, err := repository.FindEquipment(db, equipmentID)
+	if err != nil {
+		return apperr.From(notFound(err), "Failed to restore equipment")
+	}
+
+	setETag(c, equipment.Version)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":   "Equipment restored successfully",
+		"equipment": equipment,
+	})
+}
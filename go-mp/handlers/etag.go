@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"mini-project/apperr"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Equipment and rentals carry a version that is incremented by every update.
+// It is sent as a strong ETag; updates must echo it in If-Match, so that a
+// client cannot overwrite changes it has not seen.
+
+func etag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+func setETag(c echo.Context, version uint) {
+	c.Response().Header().Set("ETag", etag(version))
+}
+
+// notModified sets the ETag of a read and reports whether the client's
+// If-None-Match already names it, in which case the caller answers 304.
+func notModified(c echo.Context, version uint) bool {
+	setETag(c, version)
+
+	header := c.Request().Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	// If-None-Match uses the weak comparison.
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == etag(version) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIfMatch requires the If-Match header of an update to name the current
+// version of the resource.
+func checkIfMatch(c echo.Context, version uint) error {
+	header := c.Request().Header.Get("If-Match")
+	if header == "" {
+		return apperr.ErrIfMatchRequired
+	}
+	if strings.TrimSpace(header) == "*" {
+		return nil
+	}
+
+	// If-Match uses the strong comparison, so weak tags never match.
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimSpace(tag) == etag(version) {
+			return nil
+		}
+	}
+	return apperr.ErrStaleVersion
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"mini-project/token"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+var tokens *token.Service
+
+func SetTokenService(service *token.Service) {
+	tokens = service
+}
+
+// @Summary JSON Web Key Set
+// @Description Public keys for verifying the API's JWTs, identified by the kid header of each token
+// @ID get-jwks
+// @Produce json
+// @Success 200 {object} token.JWKSet "Verification keys"
+// @Router /.well-known/jwks.json [get]
+func JWKSHandler(c echo.Context) error {
+	c.Response().Header().Set(echo.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(http.StatusOK, tokens.JWKS())
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"errors"
+	"mini-project/apperr"
+	"mini-project/config"
+	"mini-project/helper"
+	"mini-project/loginguard"
+	"mini-project/model"
+	"mini-project/notification"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var guard *loginguard.Guard
+
+func SetLoginGuard(g *loginguard.Guard) {
+	guard = g
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// comparePassword checks password against the user's hash. When there is no
+// user it compares against a dummy hash instead, so unknown emails take as
+// long to reject as wrong passwords.
+func comparePassword(user *model.User, password string) bool {
+	if user == nil {
+		dummyHashOnce.Do(func() {
+			dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+		})
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return false
+	}
+
+	return bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) == nil
+}
+
+// throttled answers a login attempt that has to wait, the same way whether
+// or not the email belongs to an account. The wait is audited once per email
+// and IP address, not for every attempt made while it lasts.
+func throttled(c echo.Context, email string, now time.Time, retryAfter time.Duration) error {
+	email = helper.NormalizeEmail(email)
+	detail := "until " + now.Add(retryAfter).UTC().Format(time.RFC3339)
+
+	var audited int64
+	err := db.Model(&model.AuditLog{}).
+		Where("event = ? AND email = ? AND ip_address = ? AND detail = ?", model.AuditLoginThrottled, email, c.RealIP(), detail).
+		Limit(1).
+		Count(&audited).Error
+	if err != nil {
+		return apperr.Internal(err, "Failed to process login")
+	}
+	if audited == 0 {
+		if err := recordAudit(db, c, model.AuditLoginThrottled, nil, email, detail); err != nil {
+			return apperr.Internal(err, "Failed to process login")
+		}
+	}
+
+	seconds := int64(retryAfter / time.Second)
+	if retryAfter%time.Second > 0 {
+		seconds++
+	}
+	c.Response().Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+
+	return apperr.ErrTooManyLoginAttempts
+}
+
+// recordLoginFailure counts a failed password or second factor check and
+// writes the audit entries. If the failure locks a registered account, the
+// owner is emailed a link to unlock it.
+func recordLoginFailure(tx *gorm.DB, c echo.Context, email string, user *model.User, detail string) error {
+	now := time.Now()
+
+	lockedUntil, err := guard.RecordFailure(tx, email, c.RealIP(), now)
+	if err != nil {
+		return err
+	}
+
+	var userID *uint
+	if user != nil {
+		userID = &user.UserID
+	}
+	email = helper.NormalizeEmail(email)
+
+	if err := recordAudit(tx, c, model.AuditLoginFailed, userID, email, detail); err != nil {
+		return err
+	}
+	if lockedUntil == nil {
+		return nil
+	}
+
+	if err := recordAudit(tx, c, model.AuditAccountLocked, userID, email, "until "+lockedUntil.UTC().Format(time.RFC3339)); err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	return sendAccountUnlock(tx, *user, *lockedUntil)
+}
+
+// recordLoginSuccess forgives earlier failures and writes the audit entry.
+func recordLoginSuccess(tx *gorm.DB, c echo.Context, user model.User, detail string) error {
+	if err := guard.RecordSuccess(tx, user.Email, c.RealIP(), time.Now()); err != nil {
+		return err
+	}
+
+	return recordAudit(tx, c, model.AuditLoginSucceeded, &user.UserID, helper.NormalizeEmail(user.Email), detail)
+}
+
+// @Summary Unlock Account
+// @Description Lift a login lockout early with the token from the account locked email
+// @ID unlock-account
+// @Produce json
+// @Param token query string true "Account unlock token"
+// @Success 200 {object} map[string]string "Account unlocked successfully"
+// @Failure 400 {object} apperr.Problem "Invalid or expired unlock token"
+// @Failure 500 {object} apperr.Problem "Failed to unlock account"
+// @Router /account/unlock [get]
+func UnlockAccountHandler(c echo.Context) error {
+	plainToken := c.QueryParam("token")
+	if plainToken == "" {
+		return apperr.ErrInvalidUnlockToken
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var unlockToken model.AccountUnlockToken
+		if err := forUpdate(tx).Where("token_hash = ?", helper.HashToken(plainToken)).First(&unlockToken).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperr.ErrInvalidUnlockToken
+			}
+			return err
+		}
+
+		now := time.Now()
+		if unlockToken.UsedAt != nil || now.After(unlockToken.ExpiresAt) {
+			return apperr.ErrInvalidUnlockToken
+		}
+
+		var user model.User
+		if err := tx.First(&user, unlockToken.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperr.ErrInvalidUnlockToken
+			}
+			return err
+		}
+
+		if err := tx.Model(&unlockToken).Update("used_at", now).Error; err != nil {
+			return err
+		}
+
+		if err := loginguard.Unlock(tx, user.Email, now); err != nil {
+			return err
+		}
+
+		return recordAudit(tx, c, model.AuditAccountUnlocked, &user.UserID, helper.NormalizeEmail(user.Email), "unlock email")
+	})
+	if err != nil {
+		return apperr.From(err, "Failed to unlock account")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Account unlocked successfully"})
+}
+
+// sendAccountUnlock queues the email telling the user their account was
+// locked, with a single-use link to unlock it that is valid for as long as
+// the lock lasts.
+func sendAccountUnlock(tx *gorm.DB, user model.User, lockedUntil time.Time) error {
+	plainToken, err := helper.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	unlockToken := model.AccountUnlockToken{
+		UserID:    user.UserID,
+		TokenHash: helper.HashToken(plainToken),
+		ExpiresAt: lockedUntil,
+	}
+	if err := tx.Create(&unlockToken).Error; err != nil {
+		return err
+	}
+
+	return queueNotification(tx, user, notification.AccountLocked, notification.AccountLockedData{
+		Email:       user.Email,
+		LockedUntil: lockedUntil,
+		UnlockURL:   config.AppBaseURL() + "/account/unlock?token=" + url.QueryEscape(plainToken),
+	})
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"errors"
+	"mini-project/apperr"
+	"mini-project/model"
+	"mini-project/testdb"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestThrottledAuditsEachWaitOnce(t *testing.T) {
+	SetDB(testdb.Open(t))
+
+	e := echo.New()
+	now := time.Now()
+	until := now.Add(time.Minute)
+	for i := 0; i < 3; i++ {
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, "/login", nil), httptest.NewRecorder())
+		if err := throttled(c, "Renter@example.com", now, until.Sub(now)); !errors.Is(err, apperr.ErrTooManyLoginAttempts) {
+			t.Fatalf("got %v, want %v", err, apperr.ErrTooManyLoginAttempts)
+		}
+		now = now.Add(time.Second)
+	}
+
+	var audited int64
+	if err := db.Model(&model.AuditLog{}).Where("event = ?", model.AuditLoginThrottled).Count(&audited).Error; err != nil {
+		t.Fatal(err)
+	}
+	if audited != 1 {
+		t.Fatalf("got %d throttle audit entries, want 1", audited)
+	}
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"errors"
+	"mini-project/apperr"
+	"mini-project/repository"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// pathID parses the numeric ID in the path parameter name, answering 400 with
+// detail when it is not one.
+func pathID(c echo.Context, name, detail string) (uint, error) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 0)
+	if err != nil || id == 0 {
+		return 0, apperr.ErrInvalidID.WithDetail(detail)
+	}
+
+	return uint(id), nil
+}
+
+// notFound converts a repository.NotFoundError into the matching 404 error and
+// returns other errors unchanged.
+func notFound(err error) error {
+	var notFoundErr *repository.NotFoundError
+	if !errors.As(err, &notFoundErr) {
+		return err
+	}
+
+	switch notFoundErr.Resource {
+	case repository.ResourceUser:
+		return apperr.ErrUserNotFound.WithCause(err)
+	case repository.ResourceEquipment:
+		return apperr.ErrEquipmentNotFound.WithCause(err)
+	case repository.ResourceRental:
+		return apperr.ErrRentalNotFound.WithCause(err)
+	}
+
+	return err
+}
@@ -0,0 +1,414 @@
+package handlers
+
+import (
+	"errors"
+	"mini-project/apperr"
+	"mini-project/config"
+	"mini-project/helper"
+	"mini-project/middleware"
+	"mini-project/model"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const recoveryCodeCount = 10
+
+// @Summary Start MFA Enrollment
+// @Description Generate a new TOTP secret for the current user. Scan the provisioning URI as a QR code with an authenticator app, then confirm it with /mfa/confirm.
+// @ID enroll-mfa
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Success 200 {object} map[string]interface{} "Scan the provisioning URI with your authenticator app"
+// @Failure 401 {object} apperr.Problem "JWT token missing or invalid"
+// @Failure 409 {object} apperr.Problem "Two-factor authentication is already enabled"
+// @Failure 500 {object} apperr.Problem "Failed to start two-factor enrollment"
+// @Router /mfa/enroll [post]
+func EnrollMFAHandler(c echo.Context) error {
+	principal := middleware.CurrentPrincipal(c)
+
+	secret, err := helper.GenerateTOTPSecret()
+	if err != nil {
+		return apperr.Internal(err, "Failed to start two-factor enrollment")
+	}
+
+	var user model.User
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := forUpdate(tx).First(&user, principal.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperr.ErrUnauthenticated
+			}
+			return err
+		}
+		if user.MFAEnabledAt != nil {
+			return apperr.ErrMFAAlreadyEnabled
+		}
+
+		return tx.Model(&user).Update("totp_secret", secret).Error
+	})
+	if err != nil {
+		return apperr.From(err, "Failed to start two-factor enrollment")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":          "Scan the provisioning URI with your authenticator app",
+		"secret":           secret,
+		"provisioning_uri": helper.TOTPProvisioningURI(config.MFAIssuer(), user.Email, secret),
+	})
+}
+
+// @Summary Confirm MFA Enrollment
+// @Description Enable two-factor authentication by submitting a code from the authenticator app. The response lists single-use recovery codes, which are only shown once. Sign in again to obtain a session that satisfies MFA policies.
+// @ID confirm-mfa
+// @Accept json
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Param request body model.ConfirmMFARequestBody true "Code from the authenticator app"
+// @Success 200 {object} map[string]interface{} "Two-factor authentication enabled"
+// @Failure 400 {object} apperr.Problem "Invalid request body" "Invalid authentication code"
+// @Failure 401 {object} apperr.Problem "JWT token missing or invalid"
+// @Failure 409 {object} apperr.Problem "Two-factor authentication is already enabled" "Start two-factor enrollment first"
+// @Failure 422 {object} apperr.Problem "Validation failed, with field errors"
+// @Failure 500 {object} apperr.Problem "Failed to enable two-factor authentication"
+// @Router /mfa/confirm [post]
+func ConfirmMFAHandler(c echo.Context) error {
+	principal := middleware.CurrentPrincipal(c)
+
+	var requestBody model.ConfirmMFARequestBody
+	if err := c.Bind(&requestBody); err != nil {
+		return apperr.ErrInvalidRequest
+	}
+	if err := c.Validate(&requestBody); err != nil {
+		return invalidRequest(err)
+	}
+
+	var recoveryCodes []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var user model.User
+		if err := forUpdate(tx).First(&user, principal.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperr.ErrUnauthenticated
+			}
+			return err
+		}
+		if user.MFAEnabledAt != nil {
+			return apperr.ErrMFAAlreadyEnabled
+		}
+		if user.TOTPSecret == "" {
+			return apperr.ErrMFANotEnrolling
+		}
+
+		step, ok := helper.ValidateTOTP(user.TOTPSecret, requestBody.Code, time.Now())
+		if !ok {
+			return apperr.ErrInvalidMFACode
+		}
+
+		now := time.Now()
+		err := tx.Model(&user).Updates(map[string]interface{}{
+			"mfa_enabled_at": now,
+			"last_totp_step": step,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		recoveryCodes, err = replaceRecoveryCodes(tx, user.UserID)
+		return err
+	})
+	if err != nil {
+		return apperr.From(err, "Failed to enable two-factor authentication")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":        "Two-factor authentication enabled",
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+// @Summary Disable MFA
+// @Description Turn off two-factor authentication for the current user. Requires a current authenticator or recovery code, and is refused while the user's role requires MFA.
+// @ID disable-mfa
+// @Accept json
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Param request body model.MFACodeRequestBody true "Authenticator or recovery code"
+// @Success 200 {object} map[string]string "Two-factor authentication disabled"
+// @Failure 400 {object} apperr.Problem "Invalid request body" "Invalid authentication code"
+// @Failure 401 {object} apperr.Problem "JWT token missing or invalid"
+// @Failure 409 {object} apperr.Problem "Two-factor authentication is not enabled" "Two-factor authentication is required for your role"
+// @Failure 422 {object} apperr.Problem "Validation failed, with field errors"
+// @Failure 500 {object} apperr.Problem "Failed to disable two-factor authentication"
+// @Router /mfa/disable [post]
+func DisableMFAHandler(c echo.Context) error {
+	principal := middleware.CurrentPrincipal(c)
+
+	var requestBody model.MFACodeRequestBody
+	if err := c.Bind(&requestBody); err != nil {
+		return apperr.ErrInvalidRequest
+	}
+	if err := c.Validate(&requestBody); err != nil {
+		return invalidRequest(err)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var user model.User
+		if err := forUpdate(tx).First(&user, principal.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperr.ErrUnauthenticated
+			}
+			return err
+		}
+		if user.MFAEnabledAt == nil {
+			return apperr.ErrMFANotEnabled
+		}
+
+		required, err := mfaRequiredForRole(tx, user.Role)
+		if err != nil {
+			return err
+		}
+		if required {
+			return apperr.ErrMFARequiredForRole
+		}
+
+		if err := verifySecondFactor(tx, &user, requestBody.Code, requestBody.RecoveryCode); err != nil {
+			return err
+		}
+
+		err = tx.Model(&user).Updates(map[string]interface{}{
+			"mfa_enabled_at": nil,
+			"totp_secret":    "",
+			"last_totp_step": 0,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ?", user.UserID).Delete(&model.RecoveryCode{}).Error
+	})
+	if err != nil {
+		return apperr.From(err, "Failed to disable two-factor authentication")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Two-factor authentication disabled"})
+}
+
+// @Summary Complete MFA Login
+// @Description Exchange the mfa_token returned by /login, together with an authenticator code or an unused recovery code, for an access token and a refresh token
+// @ID login-mfa
+// @Accept json
+// @Produce json
+// @Param request body model.MFALoginRequestBody true "MFA challenge and code"
+// @Success 200 {object} map[string]interface{} "Login successful"
+// @Failure 400 {object} apperr.Problem "Invalid request body"
+// @Failure 401 {object} apperr.Problem "Invalid or expired MFA challenge" "Invalid authentication code"
+// @Failure 422 {object} apperr.Problem "Validation failed, with field errors"
+// @Failure 429 {object} apperr.Problem "Too many failed login attempts"
+// @Failure 500 {object} apperr.Problem "Failed to generate JWT token"
+// @Router /login/mfa [post]
+func LoginMFAHandler(c echo.Context) error {
+	var requestBody model.MFALoginRequestBody
+	if err := c.Bind(&requestBody); err != nil {
+		return apperr.ErrInvalidRequest
+	}
+	if err := c.Validate(&requestBody); err != nil {
+		return invalidRequest(err)
+	}
+
+	userID, err := tokens.ParseMFAChallengeToken(requestBody.MFAToken)
+	if err != nil {
+		return apperr.ErrInvalidMFAChallenge
+	}
+
+	var user model.User
+	if err := db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperr.ErrInvalidMFAChallenge
+		}
+		return apperr.Internal(err, "Failed to generate JWT token")
+	}
+
+	now := time.Now()
+	retryAfter, err := guard.RetryAfter(user.Email, c.RealIP(), now)
+	if err != nil {
+		return apperr.Internal(err, "Failed to generate JWT token")
+	}
+	if retryAfter > 0 {
+		return throttled(c, user.Email, now, retryAfter)
+	}
+
+	var pair model.TokenPair
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := forUpdate(tx).First(&user, userID).Error; err != nil {
+			return err
+		}
+		if user.MFAEnabledAt == nil {
+			return apperr.ErrMFANotEnabled
+		}
+
+		if err := verifySecondFactor(tx, &user, requestBody.Code, requestBody.RecoveryCode); err != nil {
+			return err
+		}
+
+		if err := recordLoginSuccess(tx, c, user, "password and second factor"); err != nil {
+			return err
+		}
+
+		var err error
+		pair, err = startSession(tx, user, true)
+		return err
+	})
+	switch {
+	case errors.Is(err, apperr.ErrMFANotEnabled):
+		return apperr.ErrInvalidMFAChallenge
+	case errors.Is(err, apperr.ErrInvalidMFACode):
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			return recordLoginFailure(tx, c, user.Email, &user, "wrong second factor")
+		}); err != nil {
+			return apperr.Internal(err, "Failed to generate JWT token")
+		}
+		return apperr.ErrInvalidMFACode.WithStatus(http.StatusUnauthorized)
+	case err != nil:
+		return apperr.Internal(err, "Failed to generate JWT token")
+	}
+
+	return loginResponse(c, pair)
+}
+
+// @Summary List MFA Policies
+// @Description List the roles and whether they require two-factor authentication (admin only)
+// @ID get-mfa-policies
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Success 200 {object} map[string]interface{} "MFA policies retrieved successfully"
+// @Failure 403 {object} apperr.Problem "Admin role required"
+// @Failure 500 {object} apperr.Problem "Failed to retrieve MFA policies"
+// @Router /admin/mfa-policies [get]
+func GetMFAPoliciesHandler(c echo.Context) error {
+	var policies []model.MFAPolicy
+	if err := db.Order("role").Find(&policies).Error; err != nil {
+		return apperr.Internal(err, "Failed to retrieve MFA policies")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "MFA policies retrieved successfully",
+		"data":    policies,
+	})
+}
+
+// @Summary Update MFA Policy
+// @Description Require or stop requiring two-factor authentication for a role (admin only). Users of the role without an MFA session are refused on role-protected endpoints until they enable MFA and sign in again.
+// @ID update-mfa-policy
+// @Accept json
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Param role path string true "Role"
+// @Param request body model.UpdateMFAPolicyRequestBody true "Policy"
+// @Success 200 {object} map[string]interface{} "MFA policy updated successfully"
+// @Failure 400 {object} apperr.Problem "Invalid request body" "Invalid role"
+// @Failure 403 {object} apperr.Problem "Admin role required"
+// @Failure 422 {object} apperr.Problem "Validation failed, with field errors"
+// @Failure 500 {object} apperr.Problem "Failed to update MFA policy"
+// @Router /admin/mfa-policies/{role} [put]
+func UpdateMFAPolicyHandler(c echo.Context) error {
+	role := c.Param("role")
+	if !model.IsValidRole(role) {
+		return apperr.ErrInvalidParameter.WithDetail("Invalid role")
+	}
+
+	var requestBody model.UpdateMFAPolicyRequestBody
+	if err := c.Bind(&requestBody); err != nil {
+		return apperr.ErrInvalidRequest
+	}
+	if err := c.Validate(&requestBody); err != nil {
+		return invalidRequest(err)
+	}
+
+	policy := model.MFAPolicy{Role: role, Required: *requestBody.Required}
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "role"}},
+		DoUpdates: clause.AssignmentColumns([]string{"required", "updated_at"}),
+	}).Create(&policy).Error
+	if err != nil {
+		return apperr.Internal(err, "Failed to update MFA policy")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "MFA policy updated successfully",
+		"data":    policy,
+	})
+}
+
+// verifySecondFactor accepts either a TOTP code that has not been used yet or
+// an unused recovery code, and consumes it. The user must be locked by tx.
+func verifySecondFactor(tx *gorm.DB, user *model.User, code, recoveryCode string) error {
+	if code != "" {
+		step, ok := helper.ValidateTOTP(user.TOTPSecret, code, time.Now())
+		if !ok || step <= user.LastTOTPStep {
+			return apperr.ErrInvalidMFACode
+		}
+
+		user.LastTOTPStep = step
+		return tx.Model(user).Update("last_totp_step", step).Error
+	}
+
+	if recoveryCode != "" {
+		result := tx.Model(&model.RecoveryCode{}).
+			Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.UserID, helper.HashToken(helper.NormalizeRecoveryCode(recoveryCode))).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return apperr.ErrInvalidMFACode
+		}
+
+		return nil
+	}
+
+	return apperr.ErrInvalidMFACode
+}
+
+// replaceRecoveryCodes discards the user's recovery codes and stores a new
+// set, returning the plain codes so they can be shown once.
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	stored := make([]model.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := helper.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		codes = append(codes, code)
+		stored = append(stored, model.RecoveryCode{
+			UserID:   userID,
+			CodeHash: helper.HashToken(helper.NormalizeRecoveryCode(code)),
+		})
+	}
+
+	if err := tx.Create(&stored).Error; err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func mfaRequiredForRole(tx *gorm.DB, role string) (bool, error) {
+	var policy model.MFAPolicy
+	err := tx.Where("role = ?", role).First(&policy).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return policy.Required, nil
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"errors"
+	"mini-project/apperr"
+	"mini-project/helper"
+	"mini-project/testdb"
+	"testing"
+	"time"
+)
+
+func TestVerifySecondFactorRejectsReplayedCode(t *testing.T) {
+	db := testdb.Open(t)
+
+	user := testdb.Renter(t, db, 0)
+	secret, err := helper.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.TOTPSecret = secret
+	if err := db.Save(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	code, err := helper.TOTPCode(user.TOTPSecret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifySecondFactor(db, &user, code, ""); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if err := verifySecondFactor(db, &user, code, ""); !errors.Is(err, apperr.ErrInvalidMFACode) {
+		t.Fatalf("replay: got %v, want %v", err, apperr.ErrInvalidMFACode)
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"errors"
+	"mini-project/apperr"
+	"mini-project/model"
+	"mini-project/outbox"
+	"mini-project/repository"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// @Summary List Outbox Messages
+// @Description List notification emails in the outbox by delivery status, newest first by default, one page at a time (admin only). Message bodies are never listed. Pages are fetched by passing the next_cursor of the previous page, with the same sort, as cursor; the next page URL is also sent in a Link header.
+// @ID list-outbox-messages
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Param status query string false "Delivery status: pending, sent or dead" default(dead)
+// @Param sort query string false "Column to sort by, prefixed with - for descending order" Enums(outbox_message_id, -outbox_message_id, next_attempt_at, -next_attempt_at, created_at, -created_at) default(-outbox_message_id)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Page size" minimum(1) maximum(100) default(20)
+// @Param include_total query bool false "Count the matches across all pages"
+// @Success 200 {object} model.OutboxMessagePage "One page of outbox messages"
+// @Failure 400 {object} apperr.Problem "Invalid status or pagination parameter"
+// @Failure 403 {object} apperr.Problem "Admin role required"
+// @Failure 500 {object} apperr.Problem "Failed to retrieve outbox messages"
+// @Router /admin/outbox [get]
+func GetOutboxMessagesHandler(c echo.Context) error {
+	status := c.QueryParam("status")
+	if status == "" {
+		status = model.OutboxStatusDead
+	}
+	if status != model.OutboxStatusPending && status != model.OutboxStatusSent && status != model.OutboxStatusDead {
+		return apperr.ErrInvalidParameter.WithDetail("Invalid status")
+	}
+
+	req, err := pageRequest(c)
+	if err != nil {
+		return err
+	}
+	if req.Sort == "" {
+		req.Sort = "-outbox_message_id"
+	}
+
+	page, err := repository.ListOutboxMessages(db, status, req)
+	if err != nil {
+		return apperr.From(pageError(err), "Failed to retrieve outbox messages")
+	}
+
+	return c.JSON(http.StatusOK, model.OutboxMessagePage{
+		Data:       page.Items,
+		NextCursor: page.NextCursor,
+		Next:       nextPageURL(c, page.NextCursor),
+		Total:      page.Total,
+	})
+}
+
+// @Summary Re-drive Outbox Message
+// @Description Queue a dead outbox message for delivery again with a fresh retry budget (admin only)
+// @ID redrive-outbox-message
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Param id path int true "Outbox message ID"
+// @Success 200 {object} map[string]interface{} "Outbox message queued for delivery"
+// @Failure 400 {object} apperr.Problem "Invalid outbox message ID"
+// @Failure 403 {object} apperr.Problem "Admin role required"
+// @Failure 404 {object} apperr.Problem "Outbox message not found"
+// @Failure 409 {object} apperr.Problem "Only dead messages can be re-driven"
+// @Failure 500 {object} apperr.Problem "Failed to re-drive outbox message"
+// @Router /admin/outbox/{id}/retry [post]
+func RedriveOutboxMessageHandler(c echo.Context) error {
+	outboxMessageID, err := pathID(c, "id", "Invalid outbox message ID")
+	if err != nil {
+		return err
+	}
+
+	msg, err := outbox.Redrive(db, outboxMessageID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return apperr.ErrOutboxMessageNotFound
+	case errors.Is(err, outbox.ErrNotRedrivable):
+		return apperr.ErrOutboxNotRedrivable
+	case err != nil:
+		return apperr.Internal(err, "Failed to re-drive outbox message")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Outbox message queued for delivery",
+		"data":    msg,
+	})
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"mini-project/repository"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// pageRequest reads the sort, cursor, limit and include_total query
+// parameters shared by paginated listings.
+func pageRequest(c echo.Context) (repository.PageRequest, error) {
+	req := repository.PageRequest{
+		Sort:   c.QueryParam("sort"),
+		Cursor: c.QueryParam("cursor"),
+		Limit:  defaultPageSize,
+	}
+
+	if raw := c.QueryParam("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			return req, invalidParameter("limit", fmt.Sprintf("must be between 1 and %d", maxPageSize))
+		}
+		req.Limit = limit
+	}
+
+	includeTotal, err := boolParam(c, "include_total")
+	if err != nil {
+		return req, err
+	}
+	req.IncludeTotal = includeTotal != nil && *includeTotal
+
+	return req, nil
+}
+
+// pageError converts the errors of a paginated listing that the client
+// caused into 400s and returns others unchanged.
+func pageError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrInvalidSort):
+		return invalidParameter("sort", "is not a sortable column")
+	case errors.Is(err, repository.ErrInvalidCursor):
+		return invalidParameter("cursor", "is invalid or does not belong to this sort order")
+	}
+
+	return err
+}
+
+// nextPageURL is the URL of the current request with cursor replaced, or ""
+// when there is no next page. It is also sent as a Link header.
+func nextPageURL(c echo.Context, cursor string) string {
+	if cursor == "" {
+		return ""
+	}
+
+	query := c.Request().URL.Query()
+	query.Set("cursor", cursor)
+	next := c.Request().URL.Path + "?" + query.Encode()
+
+	c.Response().Header().Add("Link", fmt.Sprintf(`<%s>; rel="next"`, next))
+	return next
+}
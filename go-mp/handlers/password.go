@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"mini-project/apperr"
+	"mini-project/config"
+	"mini-project/helper"
+	"mini-project/loginguard"
+	"mini-project/model"
+	"mini-project/notification"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// @Summary Forgot Password
+// @Description Email a single-use password reset link. The response is the same whether or not the email belongs to an account.
+// @ID forgot-password
+// @Accept json
+// @Produce json
+// @Param request body model.ForgotPasswordRequestBody true "Account email"
+// @Success 200 {object} map[string]string "If the email is registered, a reset link has been sent"
+// @Failure 400 {object} apperr.Problem "Invalid request body"
+// @Failure 422 {object} apperr.Problem "Validation failed, with field errors"
+// @Failure 500 {object} apperr.Problem "Failed to process password reset request"
+// @Router /password/forgot [post]
+func ForgotPasswordHandler(c echo.Context) error {
+	var requestBody model.ForgotPasswordRequestBody
+	if err := c.Bind(&requestBody); err != nil {
+		return apperr.ErrInvalidRequest
+	}
+	if err := c.Validate(&requestBody); err != nil {
+		return invalidRequest(err)
+	}
+
+	var user model.User
+	err := db.Where("lower(email) = ?", helper.NormalizeEmail(requestBody.Email)).First(&user).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperr.Internal(err, "Failed to process password reset request")
+	}
+
+	if err == nil {
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			return sendPasswordReset(tx, user)
+		}); err != nil {
+			return apperr.Internal(err, "Failed to process password reset request")
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "If the email is registered, a reset link has been sent"})
+}
+
+// @Summary Reset Password
+// @Description Set a new password with a token from the reset email. The token can be used once, every existing session of the user is revoked and any login lockout is lifted.
+// @ID reset-password
+// @Accept json
+// @Produce json
+// @Param request body model.ResetPasswordRequestBody true "Reset token and new password"
+// @Success 200 {object} map[string]string "Password reset successfully"
+// @Failure 400 {object} apperr.Problem "Invalid request body" "Invalid or expired reset token"
+// @Failure 422 {object} apperr.Problem "Validation failed, with field errors"
+// @Failure 500 {object} apperr.Problem "Failed to reset password"
+// @Router /password/reset [post]
+func ResetPasswordHandler(c echo.Context) error {
+	var requestBody model.ResetPasswordRequestBody
+	if err := c.Bind(&requestBody); err != nil {
+		return apperr.ErrInvalidRequest
+	}
+	if err := c.Validate(&requestBody); err != nil {
+		return invalidRequest(err)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(requestBody.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return apperr.Internal(err, "Failed to reset password")
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var resetToken model.PasswordResetToken
+		if err := forUpdate(tx).Where("token_hash = ?", helper.HashToken(requestBody.Token)).First(&resetToken).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperr.ErrInvalidResetToken
+			}
+			return err
+		}
+
+		now := time.Now()
+		if resetToken.UsedAt != nil || now.After(resetToken.ExpiresAt) {
+			return apperr.ErrInvalidResetToken
+		}
+
+		var user model.User
+		if err := tx.First(&user, resetToken.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperr.ErrInvalidResetToken
+			}
+			return err
+		}
+
+		if err := tx.Model(&user).Update("password", string(hashedPassword)).Error; err != nil {
+			return err
+		}
+
+		// Proving control of the mailbox is enough to lift a login lockout.
+		if err := loginguard.Unlock(tx, user.Email, now); err != nil {
+			return err
+		}
+
+		// Burn this token and any other outstanding ones for the user.
+		if err := tx.Model(&model.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", resetToken.UserID).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+
+		return revokeSessions(tx, "user_id = ?", resetToken.UserID)
+	})
+	if err != nil {
+		return apperr.From(err, "Failed to reset password")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Password reset successfully"})
+}
+
+// sendPasswordReset replaces any outstanding reset token of the user with a
+// new one and queues the email carrying it.
+func sendPasswordReset(tx *gorm.DB, user model.User) error {
+	plainToken, err := helper.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := tx.Model(&model.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", user.UserID).
+		Update("used_at", now).Error; err != nil {
+		return err
+	}
+
+	resetToken := model.PasswordResetToken{
+		UserID:    user.UserID,
+		TokenHash: helper.HashToken(plainToken),
+		ExpiresAt: now.Add(config.PasswordResetTTL()),
+	}
+	if err := tx.Create(&resetToken).Error; err != nil {
+		return err
+	}
+
+	return queueNotification(tx, user, notification.PasswordReset, notification.PasswordResetData{
+		Email:     user.Email,
+		ResetURL:  config.PasswordResetURL() + "?token=" + url.QueryEscape(plainToken),
+		ExpiresAt: resetToken.ExpiresAt,
+	})
+}
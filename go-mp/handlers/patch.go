@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"io"
+	"mime"
+	"mini-project/apperr"
+	"mini-project/helper"
+
+	"github.com/labstack/echo/v4"
+)
+
+// applyMergePatch applies the JSON Merge Patch in the request body to body,
+// which holds the current state of the resource in its request body form,
+// and validates the result like a full update.
+func applyMergePatch(c echo.Context, body interface{}) error {
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if mediaType != helper.MIMEApplicationMergePatch && mediaType != echo.MIMEApplicationJSON {
+		return apperr.ErrUnsupportedMedia.WithDetail("Content-Type must be " + helper.MIMEApplicationMergePatch)
+	}
+
+	patch, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return apperr.ErrInvalidRequest
+	}
+	if err := helper.ApplyMergePatch(body, patch); err != nil {
+		return apperr.ErrInvalidRequest.WithDetail("Invalid merge patch: " + err.Error())
+	}
+	if err := c.Validate(body); err != nil {
+		return invalidRequest(err)
+	}
+
+	return nil
+}
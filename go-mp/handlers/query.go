@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"fmt"
+	"mini-project/apperr"
+	"mini-project/helper"
+	"mini-project/middleware"
+	"mini-project/model"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// The query parameter helpers return nil when the parameter is absent and a
+// 400 naming the parameter when it is malformed.
+
+func boolParam(c echo.Context, name string) (*bool, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, invalidParameter(name, "must be true or false")
+	}
+	return &value, nil
+}
+
+func int64Param(c echo.Context, name string, min int64) (*int64, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || value < min {
+		return nil, invalidParameter(name, fmt.Sprintf("must be an integer of at least %d", min))
+	}
+	return &value, nil
+}
+
+func invalidParameter(name, problem string) error {
+	return apperr.ErrInvalidParameter.WithDetail(fmt.Sprintf("Query parameter %s %s", name, problem))
+}
+
+func uintParam(c echo.Context, name string) (*uint, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseUint(raw, 10, 0)
+	if err != nil || value == 0 {
+		return nil, invalidParameter(name, "must be a positive integer")
+	}
+	id := uint(value)
+	return &id, nil
+}
+
+func dateParam(c echo.Context, name string) (*time.Time, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := helper.ParseDate(raw)
+	if err != nil {
+		return nil, invalidParameter(name, "must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+	}
+	return &value, nil
+}
+
+// listParam reads a comma separated list of values, each of which must be
+// one of allowed.
+func listParam(c echo.Context, name string, allowed []string) ([]string, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return nil, nil
+	}
+
+	var values []string
+	for _, value := range strings.Split(raw, ",") {
+		value = strings.TrimSpace(value)
+		if !containsString(allowed, value) {
+			return nil, invalidParameter(name, "must list only "+strings.Join(allowed, ", "))
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// includeDeleted reads the include_deleted parameter, which only admins may
+// set.
+func includeDeleted(c echo.Context) (bool, error) {
+	include, err := boolParam(c, "include_deleted")
+	if err != nil || include == nil || !*include {
+		return false, err
+	}
+	if !middleware.CurrentPrincipal(c).HasRole(model.RoleAdmin) {
+		return false, apperr.ErrForbidden.WithDetail("Only admins can include deleted records")
+	}
+
+	return true, nil
+}
+
+// scoped is db, or db including soft-deleted rows when include is set.
+func scoped(include bool) *gorm.DB {
+	if include {
+		return db.Unscoped()
+	}
+	return db
+}
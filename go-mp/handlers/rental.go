@@ -1,139 +1,438 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"mini-project/apperr"
+	"mini-project/ledger"
+	"mini-project/middleware"
 	"mini-project/model"
+	"mini-project/repository"
 	"net/http"
+	"time"
 
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // @Summary Create Rental History
-// @Description Create a new rental history record
+// @Description Request a rental for the authenticated user, holding the rental costs from their deposit until the rental is settled
 // @ID create-rental-history
 // @Accept json
 // @Produce json
 // @Param authorization header string true "JWT authorization token"
+// @Param Idempotency-Key header string false "Unique key that makes retries of this request safe"
 // @Param request body model.CreateRentalHistoryRequestBody true "Request body containing rental history information"
-// @Success 200 {object} map[string]interface{} "Rental history record created successfully"
-// @Failure 400 {object} map[string]string "Invalid request body"
-// @Failure 404 {object} map[string]string "User or equipment not found"
-// @Failure 409 {object} map[string]string "Equipment is not available for rent"
-// @Failure 402 {object} map[string]string "Insufficient deposit amount"
-// @Failure 500 {object} map[string]string "Failed to create rental history"
+// @Success 201 {object} model.RentalHistory "Rental history record created successfully"
+// @Header 201 {string} Location "URL of the new rental history record"
+// @Header 201 {string} ETag "Version of the rental history record"
+// @Failure 400 {object} apperr.Problem "Invalid request body"
+// @Failure 403 {object} apperr.Problem "Email address not verified"
+// @Failure 404 {object} apperr.Problem "User or equipment not found"
+// @Failure 409 {object} apperr.Problem "Equipment is already booked for the requested dates or out of service" "Idempotency-Key reused with a different request or still in progress"
+// @Failure 402 {object} apperr.Problem "Insufficient deposit amount" "Wallet balance is negative"
+// @Failure 422 {object} apperr.Problem "Validation failed, with field errors, including a start date in the past"
+// @Failure 500 {object} apperr.Problem "Failed to create rental history"
 // @Router /rental [post]
 func CreateRentalHistoryHandler(c echo.Context) error {
 	var requestBody model.CreateRentalHistoryRequestBody
 	if err := c.Bind(&requestBody); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid request body"})
+		return apperr.ErrInvalidRequest
 	}
-
-	var user model.User
-	if err := db.First(&user, requestBody.UserID).Error; err != nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"message": "User not found"})
+	if err := c.Validate(&requestBody); err != nil {
+		return invalidRequest(err)
 	}
 
-	var equipment model.Equipment
-	if err := db.First(&equipment, requestBody.EquipmentID).Error; err != nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"message": "Equipment not found"})
-	}
+	principal := middleware.CurrentPrincipal(c)
 
-	if !equipment.Availability {
-		return c.JSON(http.StatusConflict, map[string]string{"message": "Equipment is not available for rent"})
-	}
+	var newRentalHistory model.RentalHistory
 
-	if user.DepositAmount < equipment.RentalCosts {
-		return c.JSON(http.StatusPaymentRequired, map[string]string{"message": "Insufficient deposit amount"})
-	}
+	// The equipment row lock serializes bookings of the same item, so the
+	// overlap check cannot race with a concurrent booking. The ledger locks the
+	// wallet before checking the balance for the same reason.
+	err := db.Transaction(func(tx *gorm.DB) error {
+		user, err := repository.FindUser(tx, principal.UserID)
+		if err != nil {
+			return notFound(err)
+		}
+
+		equipment, err := repository.FindEquipment(forUpdate(tx), requestBody.EquipmentID)
+		if err != nil {
+			return notFound(err)
+		}
+		if !equipment.Availability {
+			return apperr.ErrEquipmentUnavailable
+		}
+
+		overlapping, err := findOverlappingRentals(tx, equipment.EquipmentID, requestBody.StartDate, requestBody.EndDate, 0)
+		if err != nil {
+			return err
+		}
+		if len(overlapping) > 0 {
+			return apperr.ErrRentalOverlap
+		}
+
+		newRentalHistory = model.RentalHistory{
+			UserID:       user.UserID,
+			EquipmentID:  equipment.EquipmentID,
+			StartDate:    requestBody.StartDate,
+			EndDate:      requestBody.EndDate,
+			RentalStatus: model.RentalStatusRequested,
+			DepositHold:  equipment.RentalCosts,
+		}
 
-	user.DepositAmount -= equipment.RentalCosts
+		if err := tx.Create(&newRentalHistory).Error; err != nil {
+			return err
+		}
 
-	equipment.Availability = false
+		if newRentalHistory.DepositHold > 0 {
+			if _, err := ledger.HoldRentalDeposit(tx, user.UserID, newRentalHistory.RentalHistoryID, newRentalHistory.DepositHold); err != nil {
+				return err
+			}
+		}
 
-	newRentalHistory := model.RentalHistory{
-		UserID:       requestBody.UserID,
-		EquipmentID:  requestBody.EquipmentID,
-		RentalDate:   requestBody.RentalDate,
-		ReturnDate:   requestBody.ReturnDate,
-		RentalStatus: requestBody.RentalStatus,
+		return nil
+	})
+	if errors.Is(err, ledger.ErrWalletInDebt) {
+		return apperr.ErrWalletInDebt
+	}
+	if errors.Is(err, ledger.ErrInsufficientFunds) {
+		return apperr.ErrInsufficientDeposit
+	}
+	if err != nil {
+		return apperr.From(err, "Failed to create rental history")
 	}
 
-	tx := db.Begin()
+	c.Response().Header().Set(echo.HeaderLocation, fmt.Sprintf("/rental/%d", newRentalHistory.RentalHistoryID))
+	setETag(c, newRentalHistory.Version)
+	return c.JSON(http.StatusCreated, newRentalHistory)
+}
+
+// @Summary Get Rental History
+// @Description Get a rental history record by ID, with summaries of its user and equipment. Users other than admins can only get their own rentals.
+// @ID get-rental-history
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Param id path int true "Rental history ID"
+// @Param include_deleted query bool false "Find the rental even if it was deleted (admin only)"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Success 200 {object} model.RentalHistory "Rental history record"
+// @Header 200 {string} ETag "Version of the rental history record"
+// @Success 304 "Cached copy is still current"
+// @Failure 400 {object} apperr.Problem "Invalid rental history ID"
+// @Failure 401 {object} apperr.Problem "JWT token missing or invalid"
+// @Failure 403 {object} apperr.Problem "Rental belongs to another user, or only admins can include deleted records"
+// @Failure 404 {object} apperr.Problem "Rental history not found"
+// @Failure 500 {object} apperr.Problem "Failed to retrieve rental history"
+// @Router /rental/{id} [get]
+func GetRentalHistoryHandler(c echo.Context) error {
+	principal := middleware.CurrentPrincipal(c)
 
-	if err := tx.Save(&user).Error; err != nil {
-		tx.Rollback()
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to create rental history"})
+	rentalHistoryID, err := pathID(c, "id", "Invalid rental history ID")
+	if err != nil {
+		return err
 	}
-	if err := tx.Save(&equipment).Error; err != nil {
-		tx.Rollback()
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to create rental history"})
+
+	include, err := includeDeleted(c)
+	if err != nil {
+		return err
 	}
-	if err := tx.Create(&newRentalHistory).Error; err != nil {
-		tx.Rollback()
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to create rental history"})
+
+	rental, err := repository.FindRental(scoped(include).Preload("User").Preload("Equipment"), rentalHistoryID)
+	if err != nil {
+		return apperr.From(notFound(err), "Failed to retrieve rental history")
 	}
 
-	tx.Commit()
+	if rental.UserID != principal.UserID && !principal.HasRole(model.RoleAdmin) {
+		return apperr.ErrForbidden
+	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"message":          "Equipment rented successfully",
-		"user_deposit_now": user.DepositAmount,
-	})
+	if notModified(c, rental.Version) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	return c.JSON(http.StatusOK, rental)
 }
 
-// @Summary Get All Rental History
-// @Description Get a list of all rental history records
+// @Summary List Rental History
+// @Description List the rentals of the authenticated user, or of every user for admins, one page at a time. Each rental embeds a summary of its user and equipment. Filters combine with AND. Pages are fetched by passing the next_cursor of the previous page, with the same sort, as cursor; the next page URL is also sent in a Link header.
 // @ID get-all-rental-history
-// @Accept json
 // @Produce json
 // @Param authorization header string true "JWT authorization token"
-// @Success 200 {array} model.RentalHistory "List of rental history records"
-// @Failure 500 {object} map[string]string "Failed to retrieve rental history"
+// @Param user_id query int false "Only rentals of this user (admin only; other users always see their own)"
+// @Param equipment_id query int false "Only rentals of this equipment"
+// @Param status query string false "Only rentals in these statuses, comma separated" example(requested,confirmed)
+// @Param from query string false "Only rentals ending after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "Only rentals starting before this time (RFC 3339 or YYYY-MM-DD)"
+// @Param sort query string false "Column to sort by, prefixed with - for descending order" Enums(rental_history_id, -rental_history_id, start_date, -start_date, end_date, -end_date, rental_status, -rental_status) default(rental_history_id)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Page size" minimum(1) maximum(100) default(20)
+// @Param include_total query bool false "Count the matches across all pages"
+// @Param include_deleted query bool false "List deleted rentals too (admin only)"
+// @Success 200 {object} model.RentalHistoryPage "Page of rental history records"
+// @Failure 400 {object} apperr.Problem "Invalid query parameter"
+// @Failure 401 {object} apperr.Problem "JWT token missing or invalid"
+// @Failure 403 {object} apperr.Problem "Only admins can include deleted records"
+// @Failure 500 {object} apperr.Problem "Failed to retrieve rental history"
 // @Router /rental [get]
 func GetAllRentalHistoryHandler(c echo.Context) error {
-	var rentalHistory []model.RentalHistory
+	principal := middleware.CurrentPrincipal(c)
+
+	filter, err := rentalFilter(c)
+	if err != nil {
+		return err
+	}
+	if filter.UserID, err = uintParam(c, "user_id"); err != nil {
+		return err
+	}
+	if !principal.HasRole(model.RoleAdmin) {
+		filter.UserID = &principal.UserID
+	}
+	if filter.EquipmentID, err = uintParam(c, "equipment_id"); err != nil {
+		return err
+	}
 
-	if err := db.Find(&rentalHistory).Error; err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to retrieve rental history"})
+	return listRentals(c, filter, "")
+}
+
+// @Summary Equipment Rental Timeline
+// @Description List who rented an equipment item and when, ordered by start date by default (admin only). Each rental embeds a summary of its user and equipment. Paginated like the rental history listing.
+// @ID get-equipment-rentals
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Param id path int true "Equipment ID"
+// @Param status query string false "Only rentals in these statuses, comma separated" example(requested,confirmed)
+// @Param from query string false "Only rentals ending after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "Only rentals starting before this time (RFC 3339 or YYYY-MM-DD)"
+// @Param sort query string false "Column to sort by, prefixed with - for descending order" Enums(rental_history_id, -rental_history_id, start_date, -start_date, end_date, -end_date, rental_status, -rental_status) default(start_date)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Page size" minimum(1) maximum(100) default(20)
+// @Param include_total query bool false "Count the matches across all pages"
+// @Param include_deleted query bool false "List deleted rentals too (admin only)"
+// @Success 200 {object} model.RentalHistoryPage "Page of the equipment's rentals"
+// @Failure 400 {object} apperr.Problem "Invalid equipment ID or query parameter"
+// @Failure 403 {object} apperr.Problem "Admin role required"
+// @Failure 404 {object} apperr.Problem "Equipment not found"
+// @Failure 500 {object} apperr.Problem "Failed to retrieve rental history"
+// @Router /equipment/{id}/rentals [get]
+func GetEquipmentRentalsHandler(c echo.Context) error {
+	equipmentID, err := pathID(c, "id", "Invalid equipment ID")
+	if err != nil {
+		return err
+	}
+
+	filter, err := rentalFilter(c)
+	if err != nil {
+		return err
+	}
+
+	if _, err := repository.FindEquipment(scoped(filter.IncludeDeleted), equipmentID); err != nil {
+		return apperr.From(notFound(err), "Failed to retrieve rental history")
 	}
+	filter.EquipmentID = &equipmentID
 
-	return c.JSON(http.StatusOK, rentalHistory)
+	return listRentals(c, filter, "start_date")
+}
+
+// rentalFilter reads the status, from, to and include_deleted query
+// parameters shared by the rental listings.
+func rentalFilter(c echo.Context) (repository.RentalFilter, error) {
+	var filter repository.RentalFilter
+
+	var err error
+	if filter.Statuses, err = listParam(c, "status", model.RentalStatuses); err != nil {
+		return filter, err
+	}
+	if filter.From, err = dateParam(c, "from"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = dateParam(c, "to"); err != nil {
+		return filter, err
+	}
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		return filter, invalidParameter("to", "must be after from")
+	}
+	if filter.IncludeDeleted, err = includeDeleted(c); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// listRentals answers with the page of rentals matching filter, sorted by
+// defaultSort unless the request asks for another order.
+func listRentals(c echo.Context, filter repository.RentalFilter, defaultSort string) error {
+	req, err := pageRequest(c)
+	if err != nil {
+		return err
+	}
+	if req.Sort == "" {
+		req.Sort = defaultSort
+	}
+
+	page, err := repository.ListRentals(db, filter, req)
+	if err != nil {
+		return apperr.From(pageError(err), "Failed to retrieve rental history")
+	}
+
+	return c.JSON(http.StatusOK, model.RentalHistoryPage{
+		Data:       page.Items,
+		NextCursor: page.NextCursor,
+		Next:       nextPageURL(c, page.NextCursor),
+		Total:      page.Total,
+	})
 }
 
 // @Summary Update Rental History
-// @Description Update an existing rental history record
+// @Description Replace the dates of a requested or confirmed rental history record. user_id and equipment_id must match the record. New dates re-price the deposit hold at the equipment's current rental costs, holding or refunding the difference.
 // @ID update-rental-history
 // @Accept json
 // @Produce json
 // @Param authorization header string true "JWT authorization token"
 // @Param id path int true "Rental history ID to be updated"
+// @Param If-Match header string true "ETag of the version being changed"
 // @Param request body model.UpdateRentalHistoryRequestBody true "Request body containing updated rental history information"
 // @Success 200 {object} map[string]interface{} "Rental history updated successfully"
-// @Failure 400 {object} map[string]string "Invalid request body"
-// @Failure 404 {object} map[string]string "Rental history not found"
-// @Failure 500 {object} map[string]string "Failed to update rental history"
+// @Header 200 {string} ETag "New version of the rental history record"
+// @Failure 400 {object} apperr.Problem "Invalid rental history ID or request body"
+// @Failure 403 {object} apperr.Problem "Admin role required"
+// @Failure 402 {object} apperr.Problem "Insufficient deposit for the re-priced hold" "Wallet balance is negative"
+// @Failure 404 {object} apperr.Problem "Rental history or equipment not found"
+// @Failure 409 {object} apperr.Problem "Equipment is already booked for the requested dates or out of service, the rental is past confirmed, or the user or equipment was changed"
+// @Failure 412 {object} apperr.Problem "Rental history changed since it was read"
+// @Failure 422 {object} apperr.Problem "Validation failed, with field errors"
+// @Failure 428 {object} apperr.Problem "If-Match header missing"
+// @Failure 500 {object} apperr.Problem "Failed to update rental history"
 // @Router /rental/{id} [put]
 func UpdateRentalHistoryHandler(c echo.Context) error {
+	rentalHistoryID, err := pathID(c, "id", "Invalid rental history ID")
+	if err != nil {
+		return err
+	}
+
 	var requestBody model.UpdateRentalHistoryRequestBody
 	if err := c.Bind(&requestBody); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid request body"})
+		return apperr.ErrInvalidRequest
+	}
+	if err := c.Validate(&requestBody); err != nil {
+		return invalidRequest(err)
 	}
 
-	rentalHistoryID := c.Param("id")
+	return updateRental(c, rentalHistoryID, func(body *model.UpdateRentalHistoryRequestBody) error {
+		*body = requestBody
+		return nil
+	})
+}
 
-	var existingRentalHistory model.RentalHistory
-	if err := db.First(&existingRentalHistory, rentalHistoryID).Error; err != nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"message": "Rental history not found"})
+// @Summary Patch Rental History
+// @Description Change the dates of a requested or confirmed rental history record with a JSON Merge Patch (RFC 7396). Fields missing from the patch keep their values; user_id and equipment_id cannot change. New dates re-price the deposit hold like a full update.
+// @ID patch-rental-history
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Param id path int true "Rental history ID to be updated"
+// @Param If-Match header string true "ETag of the version being changed"
+// @Param request body model.UpdateRentalHistoryRequestBody true "Fields to change"
+// @Success 200 {object} map[string]interface{} "Rental history updated successfully"
+// @Header 200 {string} ETag "New version of the rental history record"
+// @Failure 400 {object} apperr.Problem "Invalid rental history ID or merge patch"
+// @Failure 403 {object} apperr.Problem "Admin role required"
+// @Failure 402 {object} apperr.Problem "Insufficient deposit for the re-priced hold" "Wallet balance is negative"
+// @Failure 404 {object} apperr.Problem "Rental history or equipment not found"
+// @Failure 409 {object} apperr.Problem "Equipment is already booked for the requested dates or out of service, the rental is past confirmed, or the user or equipment was changed"
+// @Failure 412 {object} apperr.Problem "Rental history changed since it was read"
+// @Failure 415 {object} apperr.Problem "Content-Type is not application/merge-patch+json"
+// @Failure 422 {object} apperr.Problem "Validation failed, with field errors"
+// @Failure 428 {object} apperr.Problem "If-Match header missing"
+// @Failure 500 {object} apperr.Problem "Failed to update rental history"
+// @Router /rental/{id} [patch]
+func PatchRentalHistoryHandler(c echo.Context) error {
+	rentalHistoryID, err := pathID(c, "id", "Invalid rental history ID")
+	if err != nil {
+		return err
 	}
 
-	existingRentalHistory.UserID = requestBody.UserID
-	existingRentalHistory.EquipmentID = requestBody.EquipmentID
-	existingRentalHistory.RentalDate = requestBody.RentalDate
-	existingRentalHistory.ReturnDate = requestBody.ReturnDate
-	existingRentalHistory.RentalStatus = requestBody.RentalStatus
+	return updateRental(c, rentalHistoryID, func(body *model.UpdateRentalHistoryRequestBody) error {
+		return applyMergePatch(c, body)
+	})
+}
+
+// updateRental locks the rental, checks If-Match against its version, lets
+// change edit its fields, starting from their current values, and saves the
+// result if the equipment is free. Only the dates of a requested or confirmed
+// rental can change; new dates re-price the deposit hold at the equipment's
+// current rental costs, as a new booking would be.
+func updateRental(c echo.Context, rentalHistoryID uint, change func(*model.UpdateRentalHistoryRequestBody) error) error {
+	var existingRentalHistory model.RentalHistory
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		existingRentalHistory, err = repository.FindRental(forUpdate(tx), rentalHistoryID)
+		if err != nil {
+			return notFound(err)
+		}
+		if err := checkIfMatch(c, existingRentalHistory.Version); err != nil {
+			return err
+		}
+		if !containsString(model.EditableRentalStatuses, existingRentalHistory.RentalStatus) {
+			return apperr.ErrRentalNotEditable
+		}
+
+		requestBody := model.UpdateRentalHistoryRequestBody{
+			UserID:      existingRentalHistory.UserID,
+			EquipmentID: existingRentalHistory.EquipmentID,
+			StartDate:   existingRentalHistory.StartDate,
+			EndDate:     existingRentalHistory.EndDate,
+		}
+		if err := change(&requestBody); err != nil {
+			return err
+		}
 
-	if err := db.Save(&existingRentalHistory).Error; err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to update rental history"})
+		if requestBody.UserID != existingRentalHistory.UserID || requestBody.EquipmentID != existingRentalHistory.EquipmentID {
+			return apperr.ErrRentalPartyChanged
+		}
+		if requestBody.StartDate.Equal(existingRentalHistory.StartDate) && requestBody.EndDate.Equal(existingRentalHistory.EndDate) {
+			return nil
+		}
+
+		equipment, err := repository.FindEquipment(forUpdate(tx), existingRentalHistory.EquipmentID)
+		if err != nil {
+			return notFound(err)
+		}
+		if !equipment.Availability {
+			return apperr.ErrEquipmentUnavailable
+		}
+
+		existingRentalHistory.StartDate = requestBody.StartDate
+		existingRentalHistory.EndDate = requestBody.EndDate
+
+		overlapping, err := findOverlappingRentals(tx, existingRentalHistory.EquipmentID, existingRentalHistory.StartDate, existingRentalHistory.EndDate, existingRentalHistory.RentalHistoryID)
+		if err != nil {
+			return err
+		}
+		if len(overlapping) > 0 {
+			return apperr.ErrRentalOverlap
+		}
+
+		if err := adjustDepositHold(tx, &existingRentalHistory, equipment.RentalCosts); err != nil {
+			return err
+		}
+
+		existingRentalHistory.Version++
+		return tx.Save(&existingRentalHistory).Error
+	})
+	if errors.Is(err, ledger.ErrWalletInDebt) {
+		return apperr.ErrWalletInDebt
+	}
+	if errors.Is(err, ledger.ErrInsufficientFunds) {
+		return apperr.ErrInsufficientDeposit
 	}
+	if err != nil {
+		return apperr.From(err, "Failed to update rental history")
+	}
+
+	setETag(c, existingRentalHistory.Version)
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"message": "Rental history updated successfully",
@@ -142,27 +441,142 @@ func UpdateRentalHistoryHandler(c echo.Context) error {
 }
 
 // @Summary Delete Rental History
-// @Description Delete a rental history record by ID
+// @Description Delete a cancelled or returned rental history record by ID. The record is hidden but kept until the purge job removes it, and can be restored until then. Active rentals still hold a deposit and must be cancelled or returned first.
 // @ID delete-rental-history
 // @Accept json
 // @Produce json
 // @Param authorization header string true "JWT authorization token"
 // @Param id path int true "Rental history ID to be deleted"
 // @Success 200 {object} map[string]string "Rental history deleted successfully"
-// @Failure 404 {object} map[string]string "Rental history not found"
-// @Failure 500 {object} map[string]string "Failed to delete rental history"
+// @Failure 400 {object} apperr.Problem "Invalid rental history ID"
+// @Failure 403 {object} apperr.Problem "Admin role required"
+// @Failure 404 {object} apperr.Problem "Rental history not found"
+// @Failure 409 {object} apperr.Problem "Rental is still active"
+// @Failure 500 {object} apperr.Problem "Failed to delete rental history"
 // @Router /rental/{id} [delete]
 func DeleteRentalHistoryHandler(c echo.Context) error {
-	rentalHistoryID := c.Param("id")
-
-	var existingRentalHistory model.RentalHistory
-	if err := db.First(&existingRentalHistory, rentalHistoryID).Error; err != nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"message": "Rental history not found"})
+	rentalHistoryID, err := pathID(c, "id", "Invalid rental history ID")
+	if err != nil {
+		return err
 	}
 
-	if err := db.Delete(&existingRentalHistory).Error; err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to delete rental history"})
+	// The row lock keeps a concurrent transition from reactivating the rental
+	// between the status check and the delete.
+	err = db.Transaction(func(tx *gorm.DB) error {
+		existingRentalHistory, err := repository.FindRental(forUpdate(tx), rentalHistoryID)
+		if err != nil {
+			return notFound(err)
+		}
+		if !containsString(model.InactiveRentalStatuses, existingRentalHistory.RentalStatus) {
+			return apperr.ErrRentalActive
+		}
+
+		return tx.Delete(&existingRentalHistory).Error
+	})
+	if err != nil {
+		return apperr.From(err, "Failed to delete rental history")
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{"message": "Rental history deleted successfully"})
-}
\ No newline at end of file
+}
+
+// @Summary Restore Rental History
+// @Description Undo the deletion of a rental history record (admin only). An active rental is only restored if its equipment exists and is still free for its dates. Restoring a record that is not deleted changes nothing.
+// @ID restore-rental-history
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Param id path int true "Rental history ID"
+// @Success 200 {object} map[string]interface{} "Rental history restored successfully"
+// @Header 200 {string} ETag "New version of the rental history record"
+// @Failure 400 {object} apperr.Problem "Invalid rental history ID"
+// @Failure 403 {object} apperr.Problem "Admin role required"
+// @Failure 404 {object} apperr.Problem "Rental history not found, or already purged; or its equipment is deleted"
+// @Failure 409 {object} apperr.Problem "Equipment is already booked for the rental's dates"
+// @Failure 500 {object} apperr.Problem "Failed to restore rental history"
+// @Router /rental/{id}/restore [post]
+func RestoreRentalHistoryHandler(c echo.Context) error {
+	rentalHistoryID, err := pathID(c, "id", "Invalid rental history ID")
+	if err != nil {
+		return err
+	}
+
+	var rental model.RentalHistory
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		rental, err = repository.FindRental(forUpdate(tx.Unscoped()), rentalHistoryID)
+		if err != nil {
+			return notFound(err)
+		}
+		if !rental.DeletedAt.Valid {
+			return nil
+		}
+
+		if !containsString(model.InactiveRentalStatuses, rental.RentalStatus) {
+			if _, err := repository.FindEquipment(forUpdate(tx), rental.EquipmentID); err != nil {
+				return notFound(err)
+			}
+
+			overlapping, err := findOverlappingRentals(tx, rental.EquipmentID, rental.StartDate, rental.EndDate, rental.RentalHistoryID)
+			if err != nil {
+				return err
+			}
+			if len(overlapping) > 0 {
+				return apperr.ErrRentalOverlap
+			}
+		}
+
+		rental.DeletedAt = gorm.DeletedAt{}
+		rental.Version++
+		return tx.Unscoped().Save(&rental).Error
+	})
+	if err != nil {
+		return apperr.From(err, "Failed to restore rental history")
+	}
+
+	setETag(c, rental.Version)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Rental history restored successfully",
+		"data":    rental,
+	})
+}
+
+// adjustDepositHold holds or refunds the difference between the rental's
+// current deposit hold and amount.
+func adjustDepositHold(tx *gorm.DB, rental *model.RentalHistory, amount int64) error {
+	switch diff := amount - rental.DepositHold; {
+	case diff > 0:
+		if _, err := ledger.HoldRentalDeposit(tx, rental.UserID, rental.RentalHistoryID, diff); err != nil {
+			return err
+		}
+	case diff < 0:
+		if _, err := ledger.RefundRentalHold(tx, rental.UserID, rental.RentalHistoryID, -diff); err != nil {
+			return err
+		}
+	}
+
+	rental.DepositHold = amount
+	return nil
+}
+
+// findOverlappingRentals returns the active rentals of the equipment whose date
+// range intersects [start, end). The rental with excludeID is ignored so that
+// a record can be rescheduled without conflicting with itself.
+func findOverlappingRentals(tx *gorm.DB, equipmentID uint, start, end time.Time, excludeID uint) ([]model.RentalHistory, error) {
+	var rentals []model.RentalHistory
+
+	err := tx.
+		Where("equipment_id = ? AND rental_history_id <> ?", equipmentID, excludeID).
+		Where("start_date < ? AND end_date > ?", end, start).
+		Where("rental_status NOT IN ?", model.InactiveRentalStatuses).
+		Order("start_date").
+		Find(&rentals).Error
+
+	return rentals, err
+}
+
+// forUpdate makes the next query take a row lock (SELECT ... FOR UPDATE) that
+// is held until the surrounding transaction ends.
+func forUpdate(tx *gorm.DB) *gorm.DB {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"})
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"fmt"
+	"mini-project/apperr"
+	"mini-project/config"
+	"mini-project/ledger"
+	"mini-project/middleware"
+	"mini-project/model"
+	"mini-project/notification"
+	"mini-project/repository"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// rentalSideEffect runs inside the transition transaction, after the status has
+// been validated and before the rental is saved.
+type rentalSideEffect func(tx *gorm.DB, rental *model.RentalHistory) error
+
+// @Summary Confirm Rental
+// @Description Confirm a requested rental and email the renter a confirmation (admin only)
+// @ID confirm-rental
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Param id path int true "Rental history ID"
+// @Success 200 {object} map[string]interface{} "Rental confirmed successfully"
+// @Failure 400 {object} apperr.Problem "Invalid rental history ID"
+// @Failure 403 {object} apperr.Problem "Admin role required"
+// @Failure 404 {object} apperr.Problem "Rental history not found"
+// @Failure 409 {object} apperr.Problem "Illegal status transition"
+// @Failure 500 {object} apperr.Problem "Failed to update rental status"
+// @Router /rental/{id}/confirm [post]
+func ConfirmRentalHandler(c echo.Context) error {
+	return transitionRental(c, model.RentalStatusConfirmed, "Rental confirmed successfully", func(tx *gorm.DB, rental *model.RentalHistory) error {
+		user, equipment, err := rentalParties(tx, rental)
+		if err != nil {
+			return err
+		}
+
+		return queueNotification(tx, user, notification.RentalConfirmation, notification.RentalConfirmationData{
+			RentalID:      rental.RentalHistoryID,
+			EquipmentName: equipment.Name,
+			StartDate:     rental.StartDate,
+			EndDate:       rental.EndDate,
+			DepositHold:   rental.DepositHold,
+		})
+	})
+}
+
+// @Summary Check Out Rental
+// @Description Hand a confirmed rental's equipment over to the renter (admin only)
+// @ID checkout-rental
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Param id path int true "Rental history ID"
+// @Success 200 {object} map[string]interface{} "Rental checked out successfully"
+// @Failure 400 {object} apperr.Problem "Invalid rental history ID"
+// @Failure 403 {object} apperr.Problem "Admin role required"
+// @Failure 404 {object} apperr.Problem "Rental history not found"
+// @Failure 409 {object} apperr.Problem "Illegal status transition"
+// @Failure 500 {object} apperr.Problem "Failed to update rental status"
+// @Router /rental/{id}/checkout [post]
+func CheckoutRentalHandler(c echo.Context) error {
+	return transitionRental(c, model.RentalStatusCheckedOut, "Rental checked out successfully", func(tx *gorm.DB, rental *model.RentalHistory) error {
+		now := time.Now()
+		rental.CheckedOutAt = &now
+
+		return nil
+	})
+}
+
+// @Summary Return Rental
+// @Description Record the return of a checked out or overdue rental, settling the deposit hold, charging any late return fee and emailing the renter a receipt (admin only). The late fee is charged even if it exceeds the wallet balance; the renter then cannot book again until a top-up clears the debt.
+// @ID return-rental
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Param id path int true "Rental history ID"
+// @Success 200 {object} map[string]interface{} "Rental returned successfully"
+// @Failure 400 {object} apperr.Problem "Invalid rental history ID"
+// @Failure 403 {object} apperr.Problem "Admin role required"
+// @Failure 404 {object} apperr.Problem "Rental history not found"
+// @Failure 409 {object} apperr.Problem "Illegal status transition"
+// @Failure 500 {object} apperr.Problem "Failed to update rental status"
+// @Router /rental/{id}/return [post]
+func ReturnRentalHandler(c echo.Context) error {
+	return transitionRental(c, model.RentalStatusReturned, "Rental returned successfully", func(tx *gorm.DB, rental *model.RentalHistory) error {
+		now := time.Now()
+		rental.ReturnedAt = &now
+
+		rentalCharge := rental.DepositHold
+		if rentalCharge > 0 {
+			if _, err := ledger.SettleRentalHold(tx, rental.RentalHistoryID, rentalCharge); err != nil {
+				return err
+			}
+			rental.AmountCharged += rentalCharge
+			rental.DepositHold = 0
+		}
+
+		fee := lateReturnFee(rental, now)
+		if fee > 0 {
+			if _, err := ledger.ChargeLateReturnFee(tx, rental.UserID, rental.RentalHistoryID, fee); err != nil {
+				return err
+			}
+			rental.AmountCharged += fee
+		}
+
+		user, equipment, err := rentalParties(tx, rental)
+		if err != nil {
+			return err
+		}
+
+		return queueNotification(tx, user, notification.ReturnReceipt, notification.ReturnReceiptData{
+			RentalID:      rental.RentalHistoryID,
+			EquipmentName: equipment.Name,
+			ReturnedAt:    now,
+			RentalCharge:  rentalCharge,
+			LateFee:       fee,
+			Total:         rentalCharge + fee,
+		})
+	})
+}
+
+// @Summary Mark Rental Overdue
+// @Description Flag a checked out rental that has passed its end date and email the renter a reminder (admin only)
+// @ID overdue-rental
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Param id path int true "Rental history ID"
+// @Success 200 {object} map[string]interface{} "Rental marked as overdue"
+// @Failure 400 {object} apperr.Problem "Invalid rental history ID"
+// @Failure 403 {object} apperr.Problem "Admin role required"
+// @Failure 404 {object} apperr.Problem "Rental history not found"
+// @Failure 409 {object} apperr.Problem "Illegal status transition, or the end date has not passed"
+// @Failure 500 {object} apperr.Problem "Failed to update rental status"
+// @Router /rental/{id}/overdue [post]
+func OverdueRentalHandler(c echo.Context) error {
+	return transitionRental(c, model.RentalStatusOverdue, "Rental marked as overdue", func(tx *gorm.DB, rental *model.RentalHistory) error {
+		if !time.Now().After(rental.EndDate) {
+			return apperr.ErrIllegalTransition.WithDetail("Rental is not past its end date yet")
+		}
+
+		user, equipment, err := rentalParties(tx, rental)
+		if err != nil {
+			return err
+		}
+
+		return queueNotification(tx, user, notification.OverdueReminder, notification.OverdueReminderData{
+			RentalID:      rental.RentalHistoryID,
+			EquipmentName: equipment.Name,
+			EndDate:       rental.EndDate,
+		})
+	})
+}
+
+// @Summary Cancel Rental
+// @Description Cancel a requested or confirmed rental and refund the deposit hold. Renters may only cancel their own rentals.
+// @ID cancel-rental
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Param id path int true "Rental history ID"
+// @Success 200 {object} map[string]interface{} "Rental cancelled successfully"
+// @Failure 400 {object} apperr.Problem "Invalid rental history ID"
+// @Failure 403 {object} apperr.Problem "Rental belongs to another user"
+// @Failure 404 {object} apperr.Problem "Rental history not found"
+// @Failure 409 {object} apperr.Problem "Illegal status transition"
+// @Failure 500 {object} apperr.Problem "Failed to update rental status"
+// @Router /rental/{id}/cancel [post]
+func CancelRentalHandler(c echo.Context) error {
+	return transitionRental(c, model.RentalStatusCancelled, "Rental cancelled successfully", func(tx *gorm.DB, rental *model.RentalHistory) error {
+		if rental.DepositHold == 0 {
+			return nil
+		}
+
+		if _, err := ledger.RefundRentalHold(tx, rental.UserID, rental.RentalHistoryID, rental.DepositHold); err != nil {
+			return err
+		}
+		rental.DepositHold = 0
+
+		return nil
+	})
+}
+
+// transitionRental moves the rental in the path to the target status, running
+// the side effect and the status change in a single transaction. Non-admins
+// may only transition their own rentals.
+func transitionRental(c echo.Context, to, successMessage string, sideEffect rentalSideEffect) error {
+	principal := middleware.CurrentPrincipal(c)
+	rentalHistoryID, err := pathID(c, "id", "Invalid rental history ID")
+	if err != nil {
+		return err
+	}
+
+	var rental model.RentalHistory
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		rental, err = repository.FindRental(forUpdate(tx), rentalHistoryID)
+		if err != nil {
+			return notFound(err)
+		}
+
+		if rental.UserID != principal.UserID && !principal.HasRole(model.RoleAdmin) {
+			return apperr.ErrForbidden
+		}
+
+		if !model.CanTransitionRental(rental.RentalStatus, to) {
+			return apperr.ErrIllegalTransition.WithDetail(fmt.Sprintf("Cannot change rental status from %s to %s", rental.RentalStatus, to))
+		}
+
+		rental.RentalStatus = to
+
+		if sideEffect != nil {
+			if err := sideEffect(tx, &rental); err != nil {
+				return err
+			}
+		}
+
+		rental.Version++
+		return tx.Save(&rental).Error
+	})
+	if err != nil {
+		return apperr.From(err, "Failed to update rental status")
+	}
+
+	setETag(c, rental.Version)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": successMessage,
+		"data":    rental,
+	})
+}
+
+// rentalParties loads the renter and the equipment of a rental.
+func rentalParties(tx *gorm.DB, rental *model.RentalHistory) (model.User, model.Equipment, error) {
+	user, err := repository.FindUser(tx, rental.UserID)
+	if err != nil {
+		return model.User{}, model.Equipment{}, notFound(err)
+	}
+
+	equipment, err := repository.FindEquipment(tx, rental.EquipmentID)
+	if err != nil {
+		return model.User{}, model.Equipment{}, notFound(err)
+	}
+
+	return user, equipment, nil
+}
+
+// lateReturnFee charges the configured daily fee for every started day between
+// the rental's end date and the return time.
+func lateReturnFee(rental *model.RentalHistory, returnedAt time.Time) int64 {
+	feePerDay := config.LateReturnFeePerDay()
+	if feePerDay <= 0 || !returnedAt.After(rental.EndDate) {
+		return 0
+	}
+
+	late := returnedAt.Sub(rental.EndDate)
+	days := int64(late / (24 * time.Hour))
+	if late%(24*time.Hour) > 0 {
+		days++
+	}
+
+	return days * feePerDay
+}
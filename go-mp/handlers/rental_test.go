@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"mini-project/apperr"
+	"mini-project/ledger"
+	"mini-project/middleware"
+	"mini-project/model"
+	"mini-project/testdb"
+	"mini-project/validation"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestConcurrentRentalsBookEquipmentOnce(t *testing.T) {
+	SetDB(testdb.Open(t))
+
+	user := testdb.Renter(t, db, 100000)
+	equipment := model.Equipment{Name: "Drill", Availability: true, RentalCosts: 500, Category: "tools"}
+	if err := db.Create(&equipment).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	e := echo.New()
+	e.Validator = validation.NewValidator(validation.PasswordPolicy{})
+	body := fmt.Sprintf(`{"equipment_id":%d,"start_date":"2030-01-01T00:00:00Z","end_date":"2030-01-05T00:00:00Z"}`, equipment.EquipmentID)
+
+	const attempts = 10
+
+	var wg sync.WaitGroup
+	codes := make([]int, attempts)
+	results := make([]error, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodPost, "/rental", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			middleware.SetPrincipal(c, &middleware.Principal{UserID: user.UserID, Roles: []string{model.RoleRenter}})
+
+			results[i] = CreateRentalHistoryHandler(c)
+			codes[i] = rec.Code
+		}(i)
+	}
+	wg.Wait()
+
+	booked := 0
+	for i, err := range results {
+		switch {
+		case err == nil && codes[i] == http.StatusCreated:
+			booked++
+		case errors.Is(err, apperr.ErrRentalOverlap):
+		default:
+			t.Fatalf("unexpected result: status %d, error %v", codes[i], err)
+		}
+	}
+	if booked != 1 {
+		t.Errorf("booked %d rentals, want exactly 1", booked)
+	}
+
+	var rentals int64
+	if err := db.Model(&model.RentalHistory{}).Where("equipment_id = ?", equipment.EquipmentID).Count(&rentals).Error; err != nil {
+		t.Fatal(err)
+	}
+	if rentals != 1 {
+		t.Errorf("stored %d rentals, want 1", rentals)
+	}
+
+	balance, err := ledger.WalletBalance(db, user.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance != 100000-500 {
+		t.Errorf("balance = %d, want %d", balance, 100000-500)
+	}
+}
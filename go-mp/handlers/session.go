@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"errors"
+	"mini-project/apperr"
+	"mini-project/config"
+	"mini-project/helper"
+	"mini-project/middleware"
+	"mini-project/model"
+	"mini-project/repository"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// @Summary Refresh Tokens
+// @Description Exchange a refresh token for a new access token and a new refresh token. Each refresh token can be used once; reusing one revokes the whole session.
+// @ID refresh-token
+// @Accept json
+// @Produce json
+// @Param request body model.RefreshTokenRequestBody true "Refresh token"
+// @Success 200 {object} model.TokenPair "New token pair"
+// @Failure 400 {object} apperr.Problem "Invalid request body"
+// @Failure 401 {object} apperr.Problem "Invalid or expired refresh token" "Refresh token reuse detected"
+// @Failure 422 {object} apperr.Problem "Validation failed, with field errors"
+// @Failure 500 {object} apperr.Problem "Failed to refresh token"
+// @Router /token/refresh [post]
+func RefreshTokenHandler(c echo.Context) error {
+	var requestBody model.RefreshTokenRequestBody
+	if err := c.Bind(&requestBody); err != nil {
+		return apperr.ErrInvalidRequest
+	}
+	if err := c.Validate(&requestBody); err != nil {
+		return invalidRequest(err)
+	}
+
+	var pair model.TokenPair
+	reused := false
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var stored model.RefreshToken
+		if err := forUpdate(tx).Where("token_hash = ?", helper.HashToken(requestBody.RefreshToken)).First(&stored).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperr.ErrInvalidRefreshToken
+			}
+			return err
+		}
+
+		var session model.Session
+		if err := forUpdate(tx).Where("session_id = ?", stored.SessionID).First(&session).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperr.ErrInvalidRefreshToken
+			}
+			return err
+		}
+		if session.RevokedAt != nil {
+			return apperr.ErrInvalidRefreshToken
+		}
+
+		if stored.UsedAt != nil {
+			// The token was already rotated, so someone else holds a copy.
+			reused = true
+			return revokeSessions(tx, "session_id = ?", session.SessionID)
+		}
+
+		now := time.Now()
+		if now.After(stored.ExpiresAt) {
+			return apperr.ErrInvalidRefreshToken
+		}
+
+		stored.UsedAt = &now
+		if err := tx.Save(&stored).Error; err != nil {
+			return err
+		}
+
+		var user model.User
+		if err := tx.First(&user, session.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperr.ErrInvalidRefreshToken
+			}
+			return err
+		}
+
+		var err error
+		pair, err = issueTokenPair(tx, user, session)
+		return err
+	})
+	if reused {
+		return apperr.ErrRefreshTokenReused
+	}
+	if err != nil {
+		return apperr.From(err, "Failed to refresh token")
+	}
+
+	return c.JSON(http.StatusOK, pair)
+}
+
+// @Summary Logout
+// @Description Revoke the current session, invalidating its access and refresh tokens
+// @ID logout
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Success 200 {object} map[string]string "Logged out successfully"
+// @Failure 401 {object} apperr.Problem "JWT token missing or invalid"
+// @Failure 500 {object} apperr.Problem "Failed to log out"
+// @Router /logout [post]
+func LogoutHandler(c echo.Context) error {
+	principal := middleware.CurrentPrincipal(c)
+
+	if err := revokeSessions(db, "session_id = ?", principal.SessionID); err != nil {
+		return apperr.Internal(err, "Failed to log out")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Logged out successfully"})
+}
+
+// @Summary Revoke User Sessions
+// @Description Revoke every session of a user, logging them out everywhere (admin only)
+// @ID revoke-user-sessions
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]string "User sessions revoked successfully"
+// @Failure 400 {object} apperr.Problem "Invalid user ID"
+// @Failure 403 {object} apperr.Problem "Admin role required"
+// @Failure 404 {object} apperr.Problem "User not found"
+// @Failure 500 {object} apperr.Problem "Failed to revoke user sessions"
+// @Router /admin/users/{id}/sessions/revoke [post]
+func RevokeUserSessionsHandler(c echo.Context) error {
+	userID, err := pathID(c, "id", "Invalid user ID")
+	if err != nil {
+		return err
+	}
+
+	user, err := repository.FindUser(db, userID)
+	if err != nil {
+		return apperr.From(notFound(err), "Failed to revoke user sessions")
+	}
+
+	if err := revokeSessions(db, "user_id = ?", user.UserID); err != nil {
+		return apperr.Internal(err, "Failed to revoke user sessions")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "User sessions revoked successfully"})
+}
+
+// startSession opens a new session for the user and issues its first token
+// pair. mfa records whether the login passed a second factor.
+func startSession(tx *gorm.DB, user model.User, mfa bool) (model.TokenPair, error) {
+	sessionID, err := helper.GenerateRandomToken(16)
+	if err != nil {
+		return model.TokenPair{}, err
+	}
+
+	session := model.Session{
+		SessionID: sessionID,
+		UserID:    user.UserID,
+		MFA:       mfa,
+	}
+	if err := tx.Create(&session).Error; err != nil {
+		return model.TokenPair{}, err
+	}
+
+	return issueTokenPair(tx, user, session)
+}
+
+// issueTokenPair stores a new refresh token for the session and signs a
+// matching short-lived access token.
+func issueTokenPair(tx *gorm.DB, user model.User, session model.Session) (model.TokenPair, error) {
+	refreshToken, err := helper.GenerateRandomToken(32)
+	if err != nil {
+		return model.TokenPair{}, err
+	}
+
+	stored := model.RefreshToken{
+		SessionID: session.SessionID,
+		TokenHash: helper.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(config.RefreshTokenTTL()),
+	}
+	if err := tx.Create(&stored).Error; err != nil {
+		return model.TokenPair{}, err
+	}
+
+	accessTTL := config.AccessTokenTTL()
+	accessToken, err := tokens.IssueAccessToken(user, session.SessionID, session.MFA, accessTTL)
+	if err != nil {
+		return model.TokenPair{}, err
+	}
+
+	return model.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(accessTTL.Seconds()),
+	}, nil
+}
+
+// revokeSessions marks the matching sessions as revoked.
+func revokeSessions(tx *gorm.DB, query string, args ...interface{}) error {
+	return tx.Model(&model.Session{}).
+		Where(query, args...).
+		Where("revoked_at IS NULL").
+		Update("revoked_at", time.Now()).Error
+}
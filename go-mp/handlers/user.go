@@ -1,174 +1,352 @@
 package handlers
 
 import (
+	"errors"
+	"mini-project/apperr"
+	"mini-project/config"
+	"mini-project/helper"
+	"mini-project/ledger"
+	"mini-project/middleware"
 	"mini-project/model"
-	"fmt"
+	"mini-project/notification"
+	"mini-project/repository"
+	"mini-project/validation"
 	"net/http"
-	"os"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
-	"gopkg.in/gomail.v2"
+	"gorm.io/gorm"
 )
 
 // @Summary Register a new user
-// @Description Register a new user with the provided email and password
+// @Description Register a new user with the provided email and password. Emails are case-insensitive and must be unique, and the password must satisfy the password policy. A link to confirm the email address is sent to the user.
 // @ID register-user
 // @Accept json
 // @Produce json
 // @Param request body model.RegisterRequestBody true "User registration request body"
 // @Success 200 {string} string "User registered successfully"
-// @Failure 400 {object} map[string]string "Invalid request body"
-// @Failure 500 {object} map[string]string "Failed to hash password" "Failed to create user" "Failed to send registration email"
+// @Failure 400 {object} apperr.Problem "Invalid request body"
+// @Failure 409 {object} apperr.Problem "Email is already registered"
+// @Failure 422 {object} apperr.Problem "Validation failed, with field errors"
+// @Failure 500 {object} apperr.Problem "Failed to hash password" "Failed to create user"
 // @Router /register [post]
 func RegisterUserHandler(c echo.Context) error {
-    var requestBody model.RegisterRequestBody
-    if err := c.Bind(&requestBody); err != nil {
-        return c.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid request body"})
-    }
-
-    hashedPassword, err := bcrypt.GenerateFromPassword([]byte(requestBody.Password), bcrypt.DefaultCost)
-    if err != nil {
-        return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to hash password"})
-    }
-
-    newUser := model.User{
-        Email:    requestBody.Email,
-        Password: string(hashedPassword),
-    }
-
-    if err := db.Create(&newUser).Error; err != nil {
-        return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to create user"})
-    }
-
-    if err := sendRegistrationEmail(newUser.Email); err != nil {
-        return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to send registration email"})
-    }
-
-    return c.JSON(http.StatusOK, map[string]string{"message": "User registered successfully"})
+	var requestBody model.RegisterRequestBody
+	if err := c.Bind(&requestBody); err != nil {
+		return apperr.ErrInvalidRequest
+	}
+
+	requestBody.Email = helper.NormalizeEmail(requestBody.Email)
+	if err := c.Validate(&requestBody); err != nil {
+		return invalidRequest(err)
+	}
+	email := requestBody.Email
+
+	var existing int64
+	if err := db.Model(&model.User{}).Where("lower(email) = ?", email).Count(&existing).Error; err != nil {
+		return apperr.Internal(err, "Failed to create user")
+	}
+	if existing > 0 {
+		return emailTaken()
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(requestBody.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return apperr.Internal(err, "Failed to hash password")
+	}
+
+	locale := requestBody.Locale
+	if !notification.IsSupportedLocale(locale) {
+		locale = notification.DefaultLocale
+	}
+
+	newUser := model.User{
+		Email:    email,
+		Password: string(hashedPassword),
+		Role:     model.RoleRenter,
+		Locale:   locale,
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&newUser).Error; err != nil {
+			return err
+		}
+
+		verificationURL, expiresAt, err := emailVerificationLink(newUser)
+		if err != nil {
+			return err
+		}
+
+		return queueNotification(tx, newUser, notification.Registration, notification.RegistrationData{
+			Email:           newUser.Email,
+			VerificationURL: verificationURL,
+			ExpiresAt:       expiresAt,
+		})
+	})
+	// The unique index catches registrations racing past the check above.
+	if isUniqueViolation(err) {
+		return emailTaken()
+	}
+	if err != nil {
+		return apperr.Internal(err, "Failed to create user")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "User registered successfully"})
+}
+
+func emailTaken() error {
+	var errs validation.Errors
+	errs.Add("email", validation.RuleUnique, "email is already registered")
+
+	return apperr.ErrEmailTaken.WithFields(errs)
 }
 
 // @Summary Login
-// @Description Login with the provided email and password to obtain an authentication token
+// @Description Login with the provided email and password to obtain a short-lived access token and a refresh token. Repeated failures from the same email or IP are answered with 429 and a Retry-After header, and too many lock the email for a while. Users with two-factor authentication enabled instead receive an mfa_token to exchange at /login/mfa.
 // @ID login-user
 // @Accept json
 // @Produce json
-// @Param request body model.RegisterRequestBody true "User login request body"
-// @Success 200 {object} map[string]interface{} "Login successful"
-// @Failure 400 {object} map[string]string "Invalid request body"
-// @Failure 401 {object} map[string]string "Invalid email or password"
-// @Failure 500 {object} map[string]string "Failed to generate JWT token"
+// @Param request body model.LoginRequestBody true "User login request body"
+// @Success 200 {object} map[string]interface{} "Login successful" "Two-factor authentication required"
+// @Failure 400 {object} apperr.Problem "Invalid request body"
+// @Failure 401 {object} apperr.Problem "Invalid email or password"
+// @Failure 422 {object} apperr.Problem "Validation failed, with field errors"
+// @Failure 429 {object} apperr.Problem "Too many failed login attempts"
+// @Failure 500 {object} apperr.Problem "Failed to generate JWT token"
 // @Router /login [post]
 func LoginUserHandler(c echo.Context) error {
-	var requestBody model.RegisterRequestBody
+	var requestBody model.LoginRequestBody
 	if err := c.Bind(&requestBody); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid request body"})
+		return apperr.ErrInvalidRequest
+	}
+	if err := c.Validate(&requestBody); err != nil {
+		return invalidRequest(err)
+	}
+
+	now := time.Now()
+	retryAfter, err := guard.RetryAfter(requestBody.Email, c.RealIP(), now)
+	if err != nil {
+		return apperr.Internal(err, "Failed to generate JWT token")
+	}
+	if retryAfter > 0 {
+		return throttled(c, requestBody.Email, now, retryAfter)
 	}
 
 	var user model.User
-	if err := db.Where("email = ?", requestBody.Email).First(&user).Error; err != nil {
-		return c.JSON(http.StatusUnauthorized, map[string]string{"message": "Invalid email or password"})
+	var account *model.User
+	err = db.Where("lower(email) = ?", helper.NormalizeEmail(requestBody.Email)).First(&user).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperr.Internal(err, "Failed to generate JWT token")
+	}
+	if err == nil {
+		account = &user
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(requestBody.Password)); err != nil {
-		return c.JSON(http.StatusUnauthorized, map[string]string{"message": "Invalid email or password"})
+	if !comparePassword(account, requestBody.Password) {
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			return recordLoginFailure(tx, c, requestBody.Email, account, "wrong password")
+		}); err != nil {
+			return apperr.Internal(err, "Failed to generate JWT token")
+		}
+
+		return apperr.ErrInvalidCredentials
 	}
 
-	token := jwt.New(jwt.SigningMethodHS256)
-	claims := token.Claims.(jwt.MapClaims)
-	claims["sub"] = user.UserID
-	claims["user"] = user.Email
-	claims["exp"] = time.Now().Add(time.Hour * 24).Unix()
+	// Failures are only forgiven once the second factor is checked too, so
+	// knowing the password does not reset the budget for guessing codes.
+	if user.MFAEnabledAt != nil {
+		challengeTTL := config.MFAChallengeTTL()
+		challenge, err := tokens.IssueMFAChallengeToken(user.UserID, challengeTTL)
+		if err != nil {
+			return apperr.Internal(err, "Failed to generate JWT token")
+		}
+
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"message":      "Two-factor authentication required",
+			"mfa_required": true,
+			"mfa_token":    challenge,
+			"expires_in":   int64(challengeTTL.Seconds()),
+		})
+	}
 
-	secretKey := []byte(os.Getenv("JWT_SECRET_KEY"))
-	tokenString, err := token.SignedString(secretKey)
+	var pair model.TokenPair
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := recordLoginSuccess(tx, c, user, "password"); err != nil {
+			return err
+		}
+
+		var err error
+		pair, err = startSession(tx, user, false)
+		return err
+	})
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to generate JWT token"})
+		return apperr.Internal(err, "Failed to generate JWT token")
 	}
 
+	return loginResponse(c, pair)
+}
+
+func loginResponse(c echo.Context, pair model.TokenPair) error {
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"message": "Login successful",
-		"token":   tokenString,
+		"message":       "Login successful",
+		"token":         pair.AccessToken,
+		"refresh_token": pair.RefreshToken,
+		"expires_in":    pair.ExpiresIn,
 	})
 }
 
 // @Summary Top-Up User Account
-// @Description Deposit a specified amount into the user's account balance
+// @Description Deposit a specified amount, in cents, into the user's wallet. A top-up first settles any negative balance left by late return fees.
 // @ID top-up-user
 // @Accept json
 // @Produce json
 // @Param authorization header string true "JWT authorization token"
+// @Param Idempotency-Key header string false "Unique key that makes retries of this request safe"
 // @Param deposit_amount body model.TopUpRequestBody true "Amount to deposit"
 // @Success 200 {object} map[string]interface{} "Top-up successful"
-// @Failure 400 {object} map[string]string "Invalid request body"
-// @Failure 401 {object} map[string]string "JWT token missing or invalid"
-// @Failure 404 {object} map[string]string "User not found"
-// @Failure 500 {object} map[string]string "Failed to perform top-up" "Failed to send top-up email"
+// @Failure 400 {object} apperr.Problem "Invalid request body" "Deposit amount must be positive"
+// @Failure 401 {object} apperr.Problem "JWT token missing or invalid"
+// @Failure 403 {object} apperr.Problem "Email address not verified"
+// @Failure 404 {object} apperr.Problem "User not found"
+// @Failure 409 {object} apperr.Problem "Idempotency-Key reused with a different request or still in progress"
+// @Failure 422 {object} apperr.Problem "Validation failed, with field errors"
+// @Failure 500 {object} apperr.Problem "Failed to perform top-up"
 // @Router /top-up [post]
 func TopUpUserHandler(c echo.Context) error {
-    userEmail := c.Get("user").(string)
+	principal := middleware.CurrentPrincipal(c)
 
-    var requestBody model.TopUpRequestBody
-    if err := c.Bind(&requestBody); err != nil {
-        return c.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid request body"})
-    }
-
-    var user model.User
-    if err := db.Where("email = ?", userEmail).First(&user).Error; err != nil {
-        return c.JSON(http.StatusNotFound, map[string]string{"message": "User not found"})
-    }
-
-    user.DepositAmount += requestBody.DepositAmount
+	var requestBody model.TopUpRequestBody
+	if err := c.Bind(&requestBody); err != nil {
+		return apperr.ErrInvalidRequest
+	}
+	if err := c.Validate(&requestBody); err != nil {
+		return invalidRequest(err)
+	}
 
-    if err := db.Save(&user).Error; err != nil {
-        return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to perform top-up"})
-    }
+	user, err := repository.FindUser(db, principal.UserID)
+	if err != nil {
+		return apperr.From(notFound(err), "Failed to perform top-up")
+	}
 
-    if err := sendTopUpEmail(userEmail, requestBody.DepositAmount); err != nil {
-        return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to send top-up email"})
-    }
+	var balance int64
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if _, err := ledger.TopUp(tx, user.UserID, requestBody.DepositAmount); err != nil {
+			return err
+		}
+
+		var err error
+		balance, err = ledger.WalletBalance(tx, user.UserID)
+		if err != nil {
+			return err
+		}
+
+		return queueNotification(tx, user, notification.TopUp, notification.TopUpData{
+			Amount:  requestBody.DepositAmount,
+			Balance: balance,
+		})
+	})
+	if errors.Is(err, ledger.ErrInvalidAmount) {
+		return apperr.ErrInvalidAmount
+	}
+	if err != nil {
+		return apperr.Internal(err, "Failed to perform top-up")
+	}
 
-    return c.JSON(http.StatusOK, map[string]interface{}{
-        "message": "Top-up successful",
-        "user":    user,
-    })
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Top-up successful",
+		"user":    user,
+		"balance": balance,
+	})
 }
 
-func sendRegistrationEmail(userEmail string) error {
-    m := gomail.NewMessage()
-
-    m.SetHeader("From", "tim@part.com")
-    m.SetHeader("To", userEmail)
+// @Summary Update User Role
+// @Description Change the role of a user account and sign it out of every session, so the new role applies from its next login (admin only)
+// @ID update-user-role
+// @Accept json
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Param id path int true "User ID"
+// @Param request body model.UpdateUserRoleRequestBody true "New role"
+// @Success 200 {object} map[string]interface{} "User role updated successfully"
+// @Failure 400 {object} apperr.Problem "Invalid user ID or request body"
+// @Failure 401 {object} apperr.Problem "JWT token missing or invalid"
+// @Failure 403 {object} apperr.Problem "Admin role required"
+// @Failure 404 {object} apperr.Problem "User not found"
+// @Failure 422 {object} apperr.Problem "Validation failed, with field errors"
+// @Failure 500 {object} apperr.Problem "Failed to update user role"
+// @Router /users/{id}/role [put]
+func UpdateUserRoleHandler(c echo.Context) error {
+	userID, err := pathID(c, "id", "Invalid user ID")
+	if err != nil {
+		return err
+	}
 
-    m.SetHeader("Subject", "Registration Successful")
-    m.SetBody("text/plain", "Thank you for registering with our service!")
+	var requestBody model.UpdateUserRoleRequestBody
+	if err := c.Bind(&requestBody); err != nil {
+		return apperr.ErrInvalidRequest
+	}
+	if err := c.Validate(&requestBody); err != nil {
+		return invalidRequest(err)
+	}
 
-    d := gomail.NewDialer("smtp-relay.brevo.com", 587, "timothypartaliano@gmail.com", "xsmtpsib-374b5461329392e030654874722cdd0efc42a63a024bb727deb020906b40f889-zDwMRJy37QNCh1O9")
+	var user model.User
 
-    if err := d.DialAndSend(m); err != nil {
-        return err
-    }
+	// Tokens carry the role, so the user's sessions are revoked with the
+	// change and they have to sign in again to pick up the new one.
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		user, err = repository.FindUser(tx, userID)
+		if err != nil {
+			return notFound(err)
+		}
+		if user.Role == requestBody.Role {
+			return nil
+		}
+
+		user.Role = requestBody.Role
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+
+		return revokeSessions(tx, "user_id = ?", user.UserID)
+	})
+	if err != nil {
+		return apperr.From(err, "Failed to update user role")
+	}
 
-    return nil
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "User role updated successfully",
+		"user":    user,
+	})
 }
 
-func sendTopUpEmail(userEmail string, depositAmount float64) error {
-    m := gomail.NewMessage()
-
-    m.SetHeader("From", "tim@part.com")
-    m.SetHeader("To", userEmail)
-
-    m.SetHeader("Subject", "Top-Up Successful")
-    emailBody := fmt.Sprintf("Your account has been topped up successfully with $%.2f.", depositAmount)
-    m.SetBody("text/plain", emailBody)
+// BootstrapAdmin gives the account registered with email the admin role. An
+// email nobody has registered or verified yet is only reported; register and
+// verify it, then restart. Requiring verification keeps someone who signs up
+// with the address before its owner from being promoted.
+func BootstrapAdmin(email string) error {
+	email = helper.NormalizeEmail(email)
 
-    d := gomail.NewDialer("smtp-relay.brevo.com", 587, "timothypartaliano@gmail.com", "xsmtpsib-374b5461329392e030654874722cdd0efc42a63a024bb727deb020906b40f889-zDwMRJy37QNCh1O9")
+	var user model.User
+	err := db.Where("lower(email) = ?", email).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logrus.Warnf("ADMIN_EMAIL %s is not registered; register it and restart to make it an admin", email)
+		return nil
+	}
+	if err != nil || user.Role == model.RoleAdmin {
+		return err
+	}
+	if user.EmailVerifiedAt == nil {
+		logrus.Warnf("ADMIN_EMAIL %s is not verified; verify it and restart to make it an admin", email)
+		return nil
+	}
 
-    if err := d.DialAndSend(m); err != nil {
-        return err
-    }
+	if err := db.Model(&user).Update("role", model.RoleAdmin).Error; err != nil {
+		return err
+	}
 
-    return nil
-}
\ No newline at end of file
+	logrus.Infof("Promoted %s to admin", email)
+	return nil
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"errors"
+	"mini-project/apperr"
+	"mini-project/validation"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// pgUniqueViolation is the PostgreSQL SQLSTATE for a unique constraint
+// violation.
+const pgUniqueViolation = "23505"
+
+// invalidRequest converts the error of c.Validate into the 422 listing the
+// failing fields. Any other error means the body's validate tags are broken,
+// which is a server fault.
+func invalidRequest(err error) error {
+	var errs validation.Errors
+	if errors.As(err, &errs) {
+		return apperr.Validation(errs)
+	}
+
+	return apperr.Internal(err, "Failed to validate request")
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"mini-project/apperr"
+	"mini-project/config"
+	"mini-project/middleware"
+	"mini-project/model"
+	"mini-project/notification"
+	"mini-project/repository"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// @Summary Verify Email
+// @Description Confirm ownership of an email address with the token from the verification email
+// @ID verify-email
+// @Produce json
+// @Param token query string true "Email verification token"
+// @Success 200 {object} map[string]string "Email verified successfully"
+// @Failure 400 {object} apperr.Problem "Invalid or expired verification token"
+// @Failure 500 {object} apperr.Problem "Failed to verify email"
+// @Router /verify-email [get]
+func VerifyEmailHandler(c echo.Context) error {
+	userID, email, err := tokens.ParseEmailVerificationToken(c.QueryParam("token"))
+	if err != nil {
+		return apperr.ErrInvalidVerificationToken
+	}
+
+	var user model.User
+	if err := db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperr.ErrInvalidVerificationToken
+		}
+		return apperr.Internal(err, "Failed to verify email")
+	}
+
+	// A token issued for an address the user no longer has proves nothing.
+	if user.Email != email {
+		return apperr.ErrInvalidVerificationToken
+	}
+
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+
+		if err := db.Model(&user).Update("email_verified_at", now).Error; err != nil {
+			return apperr.Internal(err, "Failed to verify email")
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Email verified successfully"})
+}
+
+// @Summary Resend Verification Email
+// @Description Send a new email verification link to the authenticated user
+// @ID resend-verification-email
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Success 200 {object} map[string]string "Verification email sent"
+// @Failure 401 {object} apperr.Problem "JWT token missing or invalid"
+// @Failure 404 {object} apperr.Problem "User not found"
+// @Failure 409 {object} apperr.Problem "Email is already verified"
+// @Failure 500 {object} apperr.Problem "Failed to send verification email"
+// @Router /verify-email/resend [post]
+func ResendVerificationEmailHandler(c echo.Context) error {
+	principal := middleware.CurrentPrincipal(c)
+
+	user, err := repository.FindUser(db, principal.UserID)
+	if err != nil {
+		return apperr.From(notFound(err), "Failed to send verification email")
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return apperr.ErrEmailAlreadyVerified
+	}
+
+	verificationURL, expiresAt, err := emailVerificationLink(user)
+	if err != nil {
+		return apperr.Internal(err, "Failed to send verification email")
+	}
+
+	err = queueNotification(db, user, notification.EmailVerification, notification.EmailVerificationData{
+		Email:           user.Email,
+		VerificationURL: verificationURL,
+		ExpiresAt:       expiresAt,
+	})
+	if err != nil {
+		return apperr.Internal(err, "Failed to send verification email")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Verification email sent"})
+}
+
+// emailVerificationLink returns the link to put in a verification email and
+// the time it stops working.
+func emailVerificationLink(user model.User) (string, time.Time, error) {
+	signed, expiresAt, err := tokens.IssueEmailVerificationToken(user.UserID, user.Email, config.EmailVerificationTTL())
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return config.AppBaseURL() + "/verify-email?token=" + url.QueryEscape(signed), expiresAt, nil
+}
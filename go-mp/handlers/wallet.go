@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"mini-project/apperr"
+	"mini-project/ledger"
+	"mini-project/middleware"
+	"mini-project/model"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// @Summary Get Wallet Balance
+// @Description Get the authenticated user's wallet balance and the amount currently held for rentals, in cents. Late return fees are charged even when they exceed the balance, so the balance can be negative; outstanding is then the debt, and new rentals are refused until top-ups have cleared it.
+// @ID get-wallet
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Success 200 {object} model.WalletBalance "Wallet balance"
+// @Failure 401 {object} apperr.Problem "JWT token missing or invalid"
+// @Failure 500 {object} apperr.Problem "Failed to retrieve wallet balance"
+// @Router /wallet [get]
+func GetWalletHandler(c echo.Context) error {
+	principal := middleware.CurrentPrincipal(c)
+
+	balance, err := ledger.WalletBalance(db, principal.UserID)
+	if err != nil {
+		return apperr.Internal(err, "Failed to retrieve wallet balance")
+	}
+
+	var held int64
+	err = db.Model(&model.RentalHistory{}).
+		Where("user_id = ?", principal.UserID).
+		Select("COALESCE(SUM(deposit_hold), 0)").
+		Scan(&held).Error
+	if err != nil {
+		return apperr.Internal(err, "Failed to retrieve wallet balance")
+	}
+
+	wallet := model.WalletBalance{Balance: balance, Held: held}
+	if balance < 0 {
+		wallet.Outstanding = -balance
+	}
+
+	return c.JSON(http.StatusOK, wallet)
+}
+
+// @Summary Get Wallet Transactions
+// @Description List every top-up, rental hold, charge, refund and fee on the authenticated user's wallet, newest first. Amounts are in cents.
+// @ID get-wallet-transactions
+// @Produce json
+// @Param authorization header string true "JWT authorization token"
+// @Success 200 {array} model.WalletTransaction "Wallet transactions"
+// @Failure 401 {object} apperr.Problem "JWT token missing or invalid"
+// @Failure 500 {object} apperr.Problem "Failed to retrieve wallet transactions"
+// @Router /wallet/transactions [get]
+func GetWalletTransactionsHandler(c echo.Context) error {
+	principal := middleware.CurrentPrincipal(c)
+
+	transactions, err := ledger.WalletTransactions(db, principal.UserID)
+	if err != nil {
+		return apperr.Internal(err, "Failed to retrieve wallet transactions")
+	}
+
+	return c.JSON(http.StatusOK, transactions)
+}
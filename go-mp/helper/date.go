@@ -0,0 +1,13 @@
+package helper
+
+import "time"
+
+// ParseDate accepts either a full RFC 3339 timestamp or a plain YYYY-MM-DD
+// date, which is interpreted as midnight UTC.
+func ParseDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	return time.Parse("2006-01-02", value)
+}
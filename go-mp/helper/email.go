@@ -0,0 +1,9 @@
+package helper
+
+import "strings"
+
+// NormalizeEmail is the canonical form emails are stored and looked up in.
+// Addresses are compared case-insensitively.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -1,21 +0,0 @@
-package helper
-
-import (
-	"time"
-
-	"github.com/golang-jwt/jwt/v5"
-)
-
-func GenerateJWT(userID uint, secretKey []byte) (string, error) {
-	token := jwt.New(jwt.SigningMethodHS256)
-	claims := token.Claims.(jwt.MapClaims)
-	claims["sub"] = userID
-	claims["exp"] = time.Now().Add(time.Hour * 24).Unix()
-
-	tokenString, err := token.SignedString(secretKey)
-	if err != nil {
-		return "", err
-	}
-
-	return tokenString, nil
-}
\ No newline at end of file
@@ -0,0 +1,66 @@
+package helper
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// MIMEApplicationMergePatch is the media type of RFC 7396 JSON Merge Patch
+// documents.
+const MIMEApplicationMergePatch = "application/merge-patch+json"
+
+// ApplyMergePatch applies an RFC 7396 JSON Merge Patch to target, which must
+// be a pointer to a value that round-trips through JSON. Members of the patch
+// replace those of target, objects are merged recursively and null removes a
+// member, leaving the zero value when target is decoded again. Members that
+// target does not have are rejected.
+func ApplyMergePatch(target interface{}, patch []byte) error {
+	original, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+
+	var doc, patchDoc interface{}
+	if err := json.Unmarshal(original, &doc); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(patch, &patchDoc); err != nil {
+		return err
+	}
+
+	merged, err := json.Marshal(mergePatch(doc, patchDoc))
+	if err != nil {
+		return err
+	}
+
+	// Start from the zero value so that removed members do not keep their
+	// old values.
+	value := reflect.ValueOf(target).Elem()
+	value.Set(reflect.Zero(value.Type()))
+
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(target)
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+		} else {
+			targetObject[name] = mergePatch(targetObject[name], value)
+		}
+	}
+
+	return targetObject
+}
@@ -0,0 +1,14 @@
+package helper
+
+import "fmt"
+
+// FormatMoney renders an amount in minor currency units as dollars and cents.
+func FormatMoney(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	return fmt.Sprintf("%s$%d.%02d", sign, amount/100, amount%100)
+}
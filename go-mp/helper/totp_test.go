@@ -0,0 +1,82 @@
+package helper
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 key of the RFC 4226 and RFC 6238 test vectors,
+// "12345678901234567890", base32 encoded.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestHOTPMatchesRFC4226Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+
+	for counter, code := range want {
+		if got := hotp(key, int64(counter)); got != code {
+			t.Errorf("hotp(%d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+func TestValidateTOTPMatchesRFC6238Vectors(t *testing.T) {
+	// The RFC lists eight digit codes; six digit codes are their last six.
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, v := range vectors {
+		step, ok := ValidateTOTP(rfcSecret, v.code, time.Unix(v.unix, 0))
+		if !ok {
+			t.Errorf("code %s rejected at %d", v.code, v.unix)
+			continue
+		}
+		if want := v.unix / totpPeriod; step != want {
+			t.Errorf("code %s matched step %d at %d, want %d", v.code, step, v.unix, want)
+		}
+	}
+}
+
+func TestValidateTOTPAcceptsOneStepOfSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := now.Unix() / totpPeriod
+
+	for offset := int64(-2); offset <= 2; offset++ {
+		code, err := TOTPCode(rfcSecret, now.Add(time.Duration(offset*totpPeriod)*time.Second))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		step, ok := ValidateTOTP(rfcSecret, code, now)
+		wantOK := offset >= -totpSkew && offset <= totpSkew
+		if ok != wantOK {
+			t.Errorf("code %d steps away accepted = %v, want %v", offset, ok, wantOK)
+		}
+		if ok && step != current+offset {
+			t.Errorf("code %d steps away matched step %d, want %d", offset, step, current+offset)
+		}
+	}
+}
+
+func TestValidateTOTPRejectsMalformedInput(t *testing.T) {
+	now := time.Unix(59, 0)
+
+	for _, c := range []struct{ secret, code string }{
+		{rfcSecret, "28708"},
+		{rfcSecret, "2870820"},
+		{"not base32!", "287082"},
+	} {
+		if _, ok := ValidateTOTP(c.secret, c.code, now); ok {
+			t.Errorf("ValidateTOTP(%q, %q) accepted", c.secret, c.code)
+		}
+	}
+}
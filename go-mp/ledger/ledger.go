@@ -0,0 +1,328 @@
+// Package ledger implements the double-entry wallet ledger. Balances are never
+// stored; they are derived from the immutable postings of journal entries.
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"mini-project/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	externalAccountCode      = "system:external"
+	depositHoldsAccountCode  = "system:deposit_holds"
+	rentalRevenueAccountCode = "system:rental_revenue"
+	feeRevenueAccountCode    = "system:fee_revenue"
+)
+
+var (
+	ErrInsufficientFunds = errors.New("insufficient wallet balance")
+	ErrWalletInDebt      = errors.New("wallet balance is negative")
+	ErrInvalidAmount     = errors.New("amount must be positive")
+	ErrUnbalancedEntry   = errors.New("journal entry postings must sum to zero")
+)
+
+// Posting is one leg of a journal entry.
+type Posting struct {
+	AccountID uint
+	Amount    int64
+}
+
+// Post records a balanced journal entry with its postings.
+func Post(tx *gorm.DB, entryType, description string, rentalHistoryID *uint, postings ...Posting) (*model.JournalEntry, error) {
+	if len(postings) < 2 {
+		return nil, ErrUnbalancedEntry
+	}
+
+	var sum int64
+	entry := model.JournalEntry{
+		EntryType:       entryType,
+		Description:     description,
+		RentalHistoryID: rentalHistoryID,
+	}
+	for _, p := range postings {
+		if p.Amount == 0 {
+			return nil, ErrInvalidAmount
+		}
+		sum += p.Amount
+		entry.Postings = append(entry.Postings, model.LedgerPosting{
+			LedgerAccountID: p.AccountID,
+			Amount:          p.Amount,
+		})
+	}
+	if sum != 0 {
+		return nil, ErrUnbalancedEntry
+	}
+
+	if err := tx.Create(&entry).Error; err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// Balance returns the sum of all postings on the account.
+func Balance(tx *gorm.DB, accountID uint) (int64, error) {
+	var balance int64
+	err := tx.Model(&model.LedgerPosting{}).
+		Where("ledger_account_id = ?", accountID).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&balance).Error
+
+	return balance, err
+}
+
+// FindWalletAccount returns the wallet account of the user, or nil if
+// nothing has been posted to it yet. Unlike the posting functions it never
+// creates the account, so reads do not write.
+func FindWalletAccount(tx *gorm.DB, userID uint) (*model.LedgerAccount, error) {
+	var accounts []model.LedgerAccount
+	if err := tx.Where("code = ?", walletCode(userID)).Limit(1).Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	if len(accounts) == 0 {
+		return nil, nil
+	}
+
+	return &accounts[0], nil
+}
+
+// WalletBalance returns the current balance of the user's wallet, zero if it
+// does not exist yet.
+func WalletBalance(tx *gorm.DB, userID uint) (int64, error) {
+	wallet, err := FindWalletAccount(tx, userID)
+	if err != nil || wallet == nil {
+		return 0, err
+	}
+
+	return Balance(tx, wallet.LedgerAccountID)
+}
+
+// WalletTransactions lists every journal entry that touched the user's wallet,
+// newest first, with the wallet balance after each entry.
+func WalletTransactions(tx *gorm.DB, userID uint) ([]model.WalletTransaction, error) {
+	wallet, err := FindWalletAccount(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if wallet == nil {
+		return []model.WalletTransaction{}, nil
+	}
+
+	var rows []model.WalletTransaction
+	err = tx.Table("ledger_postings").
+		Select("journal_entries.journal_entry_id, journal_entries.entry_type, journal_entries.description, journal_entries.rental_history_id, ledger_postings.amount, journal_entries.created_at").
+		Joins("JOIN journal_entries ON journal_entries.journal_entry_id = ledger_postings.journal_entry_id").
+		Where("ledger_postings.ledger_account_id = ?", wallet.LedgerAccountID).
+		Order("journal_entries.journal_entry_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var running int64
+	transactions := make([]model.WalletTransaction, len(rows))
+	for i, row := range rows {
+		running += row.Amount
+		row.BalanceAfter = running
+		transactions[len(rows)-1-i] = row
+	}
+
+	return transactions, nil
+}
+
+// OpeningBalance carries a balance kept outside the ledger over into the
+// user's wallet. Unlike a top-up the amount may be negative.
+func OpeningBalance(tx *gorm.DB, userID uint, amount int64) (*model.JournalEntry, error) {
+	if amount == 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	wallet, err := lockWalletAccount(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	external, err := systemAccount(tx, externalAccountCode, model.AccountTypeExternal)
+	if err != nil {
+		return nil, err
+	}
+
+	return Post(tx, model.EntryTypeOpeningBalance, "Opening balance", nil,
+		Posting{AccountID: wallet.LedgerAccountID, Amount: amount},
+		Posting{AccountID: external.LedgerAccountID, Amount: -amount},
+	)
+}
+
+// TopUp credits the user's wallet with money coming from outside the system.
+func TopUp(tx *gorm.DB, userID uint, amount int64) (*model.JournalEntry, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	wallet, err := lockWalletAccount(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	external, err := systemAccount(tx, externalAccountCode, model.AccountTypeExternal)
+	if err != nil {
+		return nil, err
+	}
+
+	return Post(tx, model.EntryTypeTopUp, "Wallet top-up", nil,
+		Posting{AccountID: wallet.LedgerAccountID, Amount: amount},
+		Posting{AccountID: external.LedgerAccountID, Amount: -amount},
+	)
+}
+
+// HoldRentalDeposit moves the rental costs from the user's wallet into the
+// deposit holds account until the rental is settled or refunded. A wallet
+// in debt takes no new holds until a top-up has cleared the debt.
+func HoldRentalDeposit(tx *gorm.DB, userID, rentalHistoryID uint, amount int64) (*model.JournalEntry, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	wallet, err := lockWalletAccount(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := Balance(tx, wallet.LedgerAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if balance < 0 {
+		return nil, ErrWalletInDebt
+	}
+	if balance < amount {
+		return nil, ErrInsufficientFunds
+	}
+
+	holds, err := systemAccount(tx, depositHoldsAccountCode, model.AccountTypeDepositHolds)
+	if err != nil {
+		return nil, err
+	}
+
+	return Post(tx, model.EntryTypeRentalHold, fmt.Sprintf("Deposit hold for rental #%d", rentalHistoryID), &rentalHistoryID,
+		Posting{AccountID: wallet.LedgerAccountID, Amount: -amount},
+		Posting{AccountID: holds.LedgerAccountID, Amount: amount},
+	)
+}
+
+// SettleRentalHold turns a deposit hold into rental revenue.
+func SettleRentalHold(tx *gorm.DB, rentalHistoryID uint, amount int64) (*model.JournalEntry, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	holds, err := systemAccount(tx, depositHoldsAccountCode, model.AccountTypeDepositHolds)
+	if err != nil {
+		return nil, err
+	}
+	revenue, err := systemAccount(tx, rentalRevenueAccountCode, model.AccountTypeRentalRevenue)
+	if err != nil {
+		return nil, err
+	}
+
+	return Post(tx, model.EntryTypeRentalCharge, fmt.Sprintf("Rental charge for rental #%d", rentalHistoryID), &rentalHistoryID,
+		Posting{AccountID: holds.LedgerAccountID, Amount: -amount},
+		Posting{AccountID: revenue.LedgerAccountID, Amount: amount},
+	)
+}
+
+// RefundRentalHold releases a deposit hold back to the user's wallet.
+func RefundRentalHold(tx *gorm.DB, userID, rentalHistoryID uint, amount int64) (*model.JournalEntry, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	wallet, err := lockWalletAccount(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	holds, err := systemAccount(tx, depositHoldsAccountCode, model.AccountTypeDepositHolds)
+	if err != nil {
+		return nil, err
+	}
+
+	return Post(tx, model.EntryTypeRentalRefund, fmt.Sprintf("Refund for rental #%d", rentalHistoryID), &rentalHistoryID,
+		Posting{AccountID: holds.LedgerAccountID, Amount: -amount},
+		Posting{AccountID: wallet.LedgerAccountID, Amount: amount},
+	)
+}
+
+// ChargeLateReturnFee debits a late return fee from the user's wallet. The
+// wallet may go negative; the debt is settled by the next top-ups, and
+// HoldRentalDeposit refuses new holds until then.
+func ChargeLateReturnFee(tx *gorm.DB, userID, rentalHistoryID uint, amount int64) (*model.JournalEntry, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	wallet, err := lockWalletAccount(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	fees, err := systemAccount(tx, feeRevenueAccountCode, model.AccountTypeFeeRevenue)
+	if err != nil {
+		return nil, err
+	}
+
+	return Post(tx, model.EntryTypeLateReturnFee, fmt.Sprintf("Late return fee for rental #%d", rentalHistoryID), &rentalHistoryID,
+		Posting{AccountID: wallet.LedgerAccountID, Amount: -amount},
+		Posting{AccountID: fees.LedgerAccountID, Amount: amount},
+	)
+}
+
+// lockWalletAccount returns the user's wallet, creating it on first use, with
+// a row lock held until the transaction ends. Every entry that moves money in or out of a wallet takes
+// this lock first, so balance checks cannot race with concurrent postings.
+func lockWalletAccount(tx *gorm.DB, userID uint) (*model.LedgerAccount, error) {
+	wallet, err := firstOrCreateAccount(tx, &model.LedgerAccount{
+		Code:   walletCode(userID),
+		Type:   model.AccountTypeUserWallet,
+		UserID: &userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(wallet, wallet.LedgerAccountID).Error; err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+func walletCode(userID uint) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+func systemAccount(tx *gorm.DB, code, accountType string) (*model.LedgerAccount, error) {
+	account := model.LedgerAccount{
+		Code: code,
+		Type: accountType,
+	}
+
+	return firstOrCreateAccount(tx, &account)
+}
+
+// firstOrCreateAccount inserts the account unless one with the same code
+// already exists, which keeps concurrent first uses from racing each other.
+func firstOrCreateAccount(tx *gorm.DB, account *model.LedgerAccount) (*model.LedgerAccount, error) {
+	err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "code"}}, DoNothing: true}).
+		Create(account).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var existing model.LedgerAccount
+	if err := tx.Where("code = ?", account.Code).First(&existing).Error; err != nil {
+		return nil, err
+	}
+
+	return &existing, nil
+}
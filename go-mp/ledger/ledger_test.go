@@ -0,0 +1,178 @@
+package ledger_test
+
+import (
+	"errors"
+	"mini-project/ledger"
+	"mini-project/testdb"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestConcurrentHoldsNeverOverdrawWallet(t *testing.T) {
+	db := testdb.Open(t)
+
+	user := testdb.Renter(t, db, 1000)
+
+	const attempts = 10
+	const hold = 300
+
+	var wg sync.WaitGroup
+	results := make([]error, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = db.Transaction(func(tx *gorm.DB) error {
+				_, err := ledger.HoldRentalDeposit(tx, user.UserID, uint(i+1), hold)
+				return err
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	held := 0
+	for _, err := range results {
+		switch {
+		case err == nil:
+			held++
+		case errors.Is(err, ledger.ErrInsufficientFunds):
+		default:
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if held != 3 {
+		t.Errorf("held %d deposits, want 3", held)
+	}
+
+	balance, err := ledger.WalletBalance(db, user.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance != 100 {
+		t.Errorf("balance = %d, want 100", balance)
+	}
+}
+
+func TestConcurrentTopUpsAndHoldsKeepBalanceNonNegative(t *testing.T) {
+	db := testdb.Open(t)
+
+	user := testdb.Renter(t, db, 100)
+
+	const rounds = 10
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	held := 0
+	for i := 0; i < rounds; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := db.Transaction(func(tx *gorm.DB) error {
+				_, err := ledger.TopUp(tx, user.UserID, 100)
+				return err
+			}); err != nil {
+				t.Errorf("top-up: %v", err)
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if _, err := ledger.HoldRentalDeposit(tx, user.UserID, uint(i+1), 150); err != nil {
+					return err
+				}
+
+				balance, err := ledger.Balance(tx, mustWallet(t, tx, user.UserID))
+				if err != nil {
+					return err
+				}
+				if balance < 0 {
+					t.Errorf("balance went negative: %d", balance)
+				}
+				return nil
+			})
+			switch {
+			case err == nil:
+				mu.Lock()
+				held++
+				mu.Unlock()
+			case !errors.Is(err, ledger.ErrInsufficientFunds):
+				t.Errorf("hold: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	balance, err := ledger.WalletBalance(db, user.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := int64(100 + rounds*100 - held*150); balance != want {
+		t.Errorf("balance = %d, want %d", balance, want)
+	}
+	if balance < 0 {
+		t.Errorf("balance went negative: %d", balance)
+	}
+}
+
+func mustWallet(t *testing.T, tx *gorm.DB, userID uint) uint {
+	wallet, err := ledger.FindWalletAccount(tx, userID)
+	if err != nil || wallet == nil {
+		t.Errorf("wallet account: %v, %v", wallet, err)
+		return 0
+	}
+
+	return wallet.LedgerAccountID
+}
+
+func TestWalletInDebtTakesNoHoldsUntilToppedUp(t *testing.T) {
+	db := testdb.Open(t)
+
+	user := testdb.Renter(t, db, 100)
+	if _, err := ledger.ChargeLateReturnFee(db, user.UserID, 1, 300); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ledger.HoldRentalDeposit(db, user.UserID, 2, 50); !errors.Is(err, ledger.ErrWalletInDebt) {
+		t.Fatalf("hold while in debt: err = %v, want ErrWalletInDebt", err)
+	}
+
+	if _, err := ledger.TopUp(db, user.UserID, 250); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ledger.HoldRentalDeposit(db, user.UserID, 2, 50); err != nil {
+		t.Fatalf("hold after clearing the debt: %v", err)
+	}
+
+	balance, err := ledger.WalletBalance(db, user.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance != 0 {
+		t.Errorf("balance = %d, want 0", balance)
+	}
+}
+
+func TestWalletReadsDoNotCreateAccount(t *testing.T) {
+	db := testdb.Open(t)
+
+	user := testdb.Renter(t, db, 0)
+
+	balance, err := ledger.WalletBalance(db, user.UserID)
+	if err != nil || balance != 0 {
+		t.Fatalf("balance = %d, %v; want 0, nil", balance, err)
+	}
+	transactions, err := ledger.WalletTransactions(db, user.UserID)
+	if err != nil || len(transactions) != 0 {
+		t.Fatalf("transactions = %v, %v; want none", transactions, err)
+	}
+
+	wallet, err := ledger.FindWalletAccount(db, user.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wallet != nil {
+		t.Errorf("reading the wallet created account %d", wallet.LedgerAccountID)
+	}
+}
@@ -0,0 +1,187 @@
+// Package loginguard throttles password guessing. Failed logins are counted
+// per email address and per client IP; once the free attempts are used up,
+// every further failure doubles the wait before the next attempt, and an
+// email address with too many failures is locked for a while.
+//
+// State is keyed by the submitted email, whether or not an account exists,
+// so the responses cannot be used to discover registered addresses.
+package loginguard
+
+import (
+	"mini-project/helper"
+	"mini-project/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Config struct {
+	// Window is how far back failures are counted.
+	Window time.Duration
+	// FreeAttempts is the number of failures per email before delays start.
+	FreeAttempts int64
+	// IPFreeAttempts is the number of failures per IP before delays start.
+	IPFreeAttempts int64
+	BaseDelay      time.Duration
+	MaxDelay       time.Duration
+	// LockoutThreshold is the number of failures per email that locks it.
+	LockoutThreshold int64
+	LockoutDuration  time.Duration
+}
+
+type Guard struct {
+	db     *gorm.DB
+	config Config
+}
+
+func New(db *gorm.DB, config Config) *Guard {
+	return &Guard{db: db, config: config}
+}
+
+// RetryAfter returns how long the client has to wait before it may try to log
+// in as email from ip again. Zero means the attempt may go ahead.
+func (g *Guard) RetryAfter(email, ip string, now time.Time) (time.Duration, error) {
+	email = helper.NormalizeEmail(email)
+
+	lockout, err := g.lockout(g.db, email)
+	if err != nil {
+		return 0, err
+	}
+	if lockout.LockedUntil != nil && lockout.LockedUntil.After(now) {
+		return lockout.LockedUntil.Sub(now), nil
+	}
+
+	since := g.countFrom(lockout, now)
+	emailWait, err := g.wait(g.db.Where("email = ?", email), since, g.config.FreeAttempts, now)
+	if err != nil {
+		return 0, err
+	}
+
+	ipWait, err := g.wait(g.db.Where("ip_address = ?", ip), now.Add(-g.config.Window), g.config.IPFreeAttempts, now)
+	if err != nil {
+		return 0, err
+	}
+
+	if ipWait > emailWait {
+		return ipWait, nil
+	}
+	return emailWait, nil
+}
+
+// RecordFailure stores a failed attempt. When it pushes the email over the
+// lockout threshold the email is locked and the lock expiry is returned.
+func (g *Guard) RecordFailure(tx *gorm.DB, email, ip string, now time.Time) (*time.Time, error) {
+	email = helper.NormalizeEmail(email)
+
+	if err := tx.Create(&model.LoginAttempt{Email: email, IPAddress: ip, CreatedAt: now}).Error; err != nil {
+		return nil, err
+	}
+
+	lockout, err := g.lockLockout(tx, email)
+	if err != nil {
+		return nil, err
+	}
+	if lockout.LockedUntil != nil && lockout.LockedUntil.After(now) {
+		return nil, nil
+	}
+
+	var failures int64
+	err = tx.Model(&model.LoginAttempt{}).
+		Where("email = ? AND succeeded = ? AND created_at > ?", email, false, g.countFrom(lockout, now)).
+		Count(&failures).Error
+	if err != nil {
+		return nil, err
+	}
+	if failures < g.config.LockoutThreshold {
+		return nil, nil
+	}
+
+	// Start counting afresh once the lock expires.
+	lockedUntil := now.Add(g.config.LockoutDuration)
+	err = tx.Model(&lockout).Updates(map[string]interface{}{
+		"locked_until": lockedUntil,
+		"reset_at":     now,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &lockedUntil, nil
+}
+
+// RecordSuccess stores a successful attempt and forgives the earlier failures
+// of the email.
+func (g *Guard) RecordSuccess(tx *gorm.DB, email, ip string, now time.Time) error {
+	email = helper.NormalizeEmail(email)
+
+	if err := tx.Create(&model.LoginAttempt{Email: email, IPAddress: ip, Succeeded: true, CreatedAt: now}).Error; err != nil {
+		return err
+	}
+
+	return Unlock(tx, email, now)
+}
+
+// Unlock lifts the lock on email and forgives its earlier failures.
+func Unlock(tx *gorm.DB, email string, now time.Time) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "email"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"locked_until": nil, "reset_at": now, "updated_at": now}),
+	}).Create(&model.LoginLockout{Email: helper.NormalizeEmail(email), ResetAt: now}).Error
+}
+
+// wait applies the progressive delay to the failures matched by scope.
+func (g *Guard) wait(scope *gorm.DB, since time.Time, freeAttempts int64, now time.Time) (time.Duration, error) {
+	var result struct {
+		Failures    int64
+		LastFailure *time.Time
+	}
+	err := scope.Model(&model.LoginAttempt{}).
+		Select("COUNT(*) AS failures, MAX(created_at) AS last_failure").
+		Where("succeeded = ? AND created_at > ?", false, since).
+		Scan(&result).Error
+	if err != nil {
+		return 0, err
+	}
+	if result.Failures < freeAttempts || result.LastFailure == nil {
+		return 0, nil
+	}
+
+	delay := g.config.MaxDelay
+	if shift := result.Failures - freeAttempts; shift < 32 {
+		if d := g.config.BaseDelay << shift; d > 0 && d < delay {
+			delay = d
+		}
+	}
+
+	return result.LastFailure.Add(delay).Sub(now), nil
+}
+
+// countFrom is the time from which failures of the email count.
+func (g *Guard) countFrom(lockout model.LoginLockout, now time.Time) time.Time {
+	since := now.Add(-g.config.Window)
+	if lockout.ResetAt.After(since) {
+		return lockout.ResetAt
+	}
+	return since
+}
+
+func (g *Guard) lockout(tx *gorm.DB, email string) (model.LoginLockout, error) {
+	var lockout model.LoginLockout
+	err := tx.Where("email = ?", email).Limit(1).Find(&lockout).Error
+	return lockout, err
+}
+
+// lockLockout creates the lockout row of the email if needed and locks it, so
+// concurrent failures cannot both miss the threshold.
+func (g *Guard) lockLockout(tx *gorm.DB, email string) (model.LoginLockout, error) {
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.LoginLockout{Email: email}).Error
+	if err != nil {
+		return model.LoginLockout{}, err
+	}
+
+	var lockout model.LoginLockout
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("email = ?", email).First(&lockout).Error
+	return lockout, err
+}
@@ -0,0 +1,63 @@
+package mailer
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailer writes every message as an .eml file into a maildir, so local
+// development never talks to a real mail server. Messages land in dir/new.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, err
+		}
+	}
+
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+func (m *FileMailer) Send(msg Message) error {
+	gm := buildMessage(m.from, msg)
+
+	name, err := uniqueName()
+	if err != nil {
+		return err
+	}
+
+	// Maildir delivery: write into tmp, then atomically move into new.
+	tmpPath := filepath.Join(m.dir, "tmp", name)
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	if _, err := gm.WriteTo(f); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return os.Rename(tmpPath, filepath.Join(m.dir, "new", name))
+}
+
+func uniqueName() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%d.%s.eml", time.Now().UnixNano(), hex.EncodeToString(b)), nil
+}
@@ -0,0 +1,37 @@
+// Package mailer delivers outgoing email through interchangeable backends.
+package mailer
+
+import (
+	"time"
+
+	"gopkg.in/gomail.v2"
+)
+
+// Message is a single email. Body is the plain-text part; when HTMLBody is set
+// the message is sent as multipart/alternative with both parts.
+type Message struct {
+	To       string
+	Subject  string
+	Body     string
+	HTMLBody string
+}
+
+// Mailer sends email messages.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+func buildMessage(from string, msg Message) *gomail.Message {
+	gm := gomail.NewMessage()
+
+	gm.SetHeader("From", from)
+	gm.SetHeader("To", msg.To)
+	gm.SetHeader("Subject", msg.Subject)
+	gm.SetDateHeader("Date", time.Now())
+	gm.SetBody("text/plain", msg.Body)
+	if msg.HTMLBody != "" {
+		gm.AddAlternative("text/html", msg.HTMLBody)
+	}
+
+	return gm
+}
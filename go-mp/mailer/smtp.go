@@ -0,0 +1,30 @@
+package mailer
+
+import "gopkg.in/gomail.v2"
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPMailer sends messages through an SMTP relay.
+type SMTPMailer struct {
+	config SMTPConfig
+	dialer *gomail.Dialer
+}
+
+func NewSMTPMailer(config SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{
+		config: config,
+		dialer: gomail.NewDialer(config.Host, config.Port, config.Username, config.Password),
+	}
+}
+
+func (m *SMTPMailer) Send(msg Message) error {
+	gm := buildMessage(m.config.From, msg)
+
+	return m.dialer.DialAndSend(gm)
+}
@@ -1,14 +1,23 @@
 package main
 
 import (
+	"context"
+	"mini-project/apperr"
 	"mini-project/config"
 	"mini-project/handlers"
+	"mini-project/loginguard"
 	"mini-project/middleware"
+	"mini-project/migrate"
 	"mini-project/model"
+	"mini-project/outbox"
+	"mini-project/purge"
+	"mini-project/validation"
+
+	_ "mini-project/docs"
 
 	"github.com/labstack/echo/v4"
-    "github.com/swaggo/echo-swagger"
-    _ "mini-project/docs"
+	"github.com/sirupsen/logrus"
+	"github.com/swaggo/echo-swagger"
 )
 
 // @title Manufacturer Go API
@@ -21,26 +30,93 @@ import (
 // @license.url https://opensource.org/licenses/MIT
 func main() {
 	db := config.InitDatabase()
-	db.AutoMigrate(&model.User{}, &model.Equipment{}, &model.RentalHistory{})
+	if err := migrate.Run(db); err != nil {
+		logrus.Fatalf("Error migrating the database: %v", err)
+	}
+
+	tokenService := config.InitTokenService()
 
 	handlers.SetDB(db)
+	if email := config.AdminEmail(); email != "" {
+		if err := handlers.BootstrapAdmin(email); err != nil {
+			logrus.Fatalf("Error promoting ADMIN_EMAIL to admin: %v", err)
+		}
+	}
+	handlers.SetTemplates(config.InitTemplates())
+	handlers.SetTokenService(tokenService)
+	handlers.SetLoginGuard(loginguard.New(db, config.LoginGuardConfig()))
+	middleware.SetDB(db)
+	middleware.SetTokenService(tokenService)
+
+	worker := outbox.NewWorker(db, config.InitMailer(), config.OutboxWorkerConfig())
+	go worker.Run(context.Background())
+	go purge.NewJob(db, config.PurgeJobConfig()).Run(context.Background())
 
 	e := echo.New()
+	e.IPExtractor = config.IPExtractor()
+	e.Validator = validation.NewValidator(config.PasswordPolicy())
+	e.HTTPErrorHandler = apperr.HTTPErrorHandler
+	e.Use(middleware.RequestID)
+	e.Use(middleware.Recover)
+
+	e.GET("/.well-known/jwks.json", handlers.JWKSHandler)
 
 	e.POST("/register", handlers.RegisterUserHandler)
 	e.POST("/login", handlers.LoginUserHandler)
+	e.POST("/login/mfa", handlers.LoginMFAHandler)
+	e.POST("/token/refresh", handlers.RefreshTokenHandler)
+	e.POST("/logout", handlers.LogoutHandler, middleware.JWTMiddleware)
+	e.POST("/password/forgot", handlers.ForgotPasswordHandler)
+	e.POST("/password/reset", handlers.ResetPasswordHandler)
+	e.GET("/account/unlock", handlers.UnlockAccountHandler)
+
+	adminOnly := middleware.RequireRoles(model.RoleAdmin)
+	anyRole := middleware.RequireRoles(model.RoleAdmin, model.RoleRenter)
+	idempotent := middleware.Idempotency(config.IdempotencyKeyTTL())
+
+	e.GET("/verify-email", handlers.VerifyEmailHandler)
+	e.POST("/verify-email/resend", handlers.ResendVerificationEmailHandler, middleware.JWTMiddleware)
+
+	e.POST("/mfa/enroll", handlers.EnrollMFAHandler, middleware.JWTMiddleware)
+	e.POST("/mfa/confirm", handlers.ConfirmMFAHandler, middleware.JWTMiddleware)
+	e.POST("/mfa/disable", handlers.DisableMFAHandler, middleware.JWTMiddleware)
+
+	e.POST("/top-up", handlers.TopUpUserHandler, middleware.JWTMiddleware, anyRole, middleware.RequireVerifiedEmail, idempotent)
+
+	e.GET("/wallet", handlers.GetWalletHandler, middleware.JWTMiddleware, anyRole)
+	e.GET("/wallet/transactions", handlers.GetWalletTransactionsHandler, middleware.JWTMiddleware, anyRole)
+
+	e.PUT("/users/:id/role", handlers.UpdateUserRoleHandler, middleware.JWTMiddleware, adminOnly)
+	e.POST("/admin/users/:id/sessions/revoke", handlers.RevokeUserSessionsHandler, middleware.JWTMiddleware, adminOnly)
+	e.GET("/admin/mfa-policies", handlers.GetMFAPoliciesHandler, middleware.JWTMiddleware, adminOnly)
+	e.PUT("/admin/mfa-policies/:role", handlers.UpdateMFAPolicyHandler, middleware.JWTMiddleware, adminOnly)
+	e.GET("/admin/audit-logs", handlers.GetAuditLogsHandler, middleware.JWTMiddleware, adminOnly)
 
-	e.POST("/top-up", handlers.TopUpUserHandler, middleware.JWTMiddleware)
+	e.GET("/admin/outbox", handlers.GetOutboxMessagesHandler, middleware.JWTMiddleware, adminOnly)
+	e.POST("/admin/outbox/:id/retry", handlers.RedriveOutboxMessageHandler, middleware.JWTMiddleware, adminOnly)
 
-	e.GET("/equipment", handlers.GetAllEquipmentHandler, middleware.JWTMiddleware)
-	e.POST("/equipment", handlers.CreateEquipmentHandler, middleware.JWTMiddleware)
-	e.PUT("/equipment/:id", handlers.UpdateEquipmentHandler, middleware.JWTMiddleware)
-	e.DELETE("/equipment/:id", handlers.DeleteEquipmentHandler, middleware.JWTMiddleware)
+	e.GET("/equipment", handlers.GetAllEquipmentHandler, middleware.JWTMiddleware, anyRole)
+	e.GET("/equipment/:id", handlers.GetEquipmentHandler, middleware.JWTMiddleware, anyRole)
+	e.GET("/equipment/:id/availability", handlers.GetEquipmentAvailabilityHandler, middleware.JWTMiddleware, anyRole)
+	e.GET("/equipment/:id/rentals", handlers.GetEquipmentRentalsHandler, middleware.JWTMiddleware, adminOnly)
+	e.POST("/equipment", handlers.CreateEquipmentHandler, middleware.JWTMiddleware, adminOnly)
+	e.PUT("/equipment/:id", handlers.UpdateEquipmentHandler, middleware.JWTMiddleware, adminOnly)
+	e.PATCH("/equipment/:id", handlers.PatchEquipmentHandler, middleware.JWTMiddleware, adminOnly)
+	e.DELETE("/equipment/:id", handlers.DeleteEquipmentHandler, middleware.JWTMiddleware, adminOnly)
+	e.POST("/equipment/:id/restore", handlers.RestoreEquipmentHandler, middleware.JWTMiddleware, adminOnly)
 
-	e.GET("/rental", handlers.GetAllRentalHistoryHandler, middleware.JWTMiddleware)
-	e.POST("/rental", handlers.CreateRentalHistoryHandler, middleware.JWTMiddleware)
-	e.PUT("/rental/:id", handlers.UpdateRentalHistoryHandler, middleware.JWTMiddleware)
-	e.DELETE("/rental/:id", handlers.DeleteRentalHistoryHandler, middleware.JWTMiddleware)
+	e.GET("/rental", handlers.GetAllRentalHistoryHandler, middleware.JWTMiddleware, anyRole)
+	e.POST("/rental", handlers.CreateRentalHistoryHandler, middleware.JWTMiddleware, anyRole, middleware.RequireVerifiedEmail, idempotent)
+	e.GET("/rental/:id", handlers.GetRentalHistoryHandler, middleware.JWTMiddleware, anyRole)
+	e.POST("/rental/:id/confirm", handlers.ConfirmRentalHandler, middleware.JWTMiddleware, adminOnly)
+	e.POST("/rental/:id/checkout", handlers.CheckoutRentalHandler, middleware.JWTMiddleware, adminOnly)
+	e.POST("/rental/:id/return", handlers.ReturnRentalHandler, middleware.JWTMiddleware, adminOnly)
+	e.POST("/rental/:id/overdue", handlers.OverdueRentalHandler, middleware.JWTMiddleware, adminOnly)
+	e.POST("/rental/:id/cancel", handlers.CancelRentalHandler, middleware.JWTMiddleware, anyRole)
+	e.PUT("/rental/:id", handlers.UpdateRentalHistoryHandler, middleware.JWTMiddleware, adminOnly)
+	e.PATCH("/rental/:id", handlers.PatchRentalHistoryHandler, middleware.JWTMiddleware, adminOnly)
+	e.DELETE("/rental/:id", handlers.DeleteRentalHistoryHandler, middleware.JWTMiddleware, adminOnly)
+	e.POST("/rental/:id/restore", handlers.RestoreRentalHistoryHandler, middleware.JWTMiddleware, adminOnly)
 
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
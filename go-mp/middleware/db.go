@@ -0,0 +1,9 @@
+package middleware
+
+import "gorm.io/gorm"
+
+var db *gorm.DB
+
+func SetDB(database *gorm.DB) {
+	db = database
+}
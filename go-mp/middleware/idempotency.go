@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mini-project/apperr"
+	"mini-project/model"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotencyInProgressCode = 0
+)
+
+// Idempotency makes retries of a money-moving request safe. The first response
+// for an Idempotency-Key is stored per user and replayed for later requests
+// with the same key and payload until the key expires after ttl. Requests
+// without the header are passed through unchanged. It must run after
+// JWTMiddleware.
+func Idempotency(ttl time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				return next(c)
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				return apperr.ErrIdempotencyKeyTooLong
+			}
+
+			principal := CurrentPrincipal(c)
+			if principal == nil {
+				return apperr.ErrUnauthenticated
+			}
+
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return apperr.ErrInvalidRequest
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			requestHash := hashRequest(c.Request(), body)
+			now := time.Now()
+
+			if err := db.Where("user_id = ? AND key = ? AND expires_at <= ?", principal.UserID, key, now).
+				Delete(&model.IdempotencyKey{}).Error; err != nil {
+				return apperr.Internal(err, "Failed to process Idempotency-Key")
+			}
+
+			record := model.IdempotencyKey{
+				UserID:      principal.UserID,
+				Key:         key,
+				RequestHash: requestHash,
+				StatusCode:  idempotencyInProgressCode,
+				ExpiresAt:   now.Add(ttl),
+			}
+			result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+			if result.Error != nil {
+				return apperr.Internal(result.Error, "Failed to process Idempotency-Key")
+			}
+
+			if result.RowsAffected == 0 {
+				return replayIdempotentResponse(c, principal.UserID, key, requestHash)
+			}
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+
+			defer func() {
+				// A panicking handler never completes the key; release it
+				// so the retry is not refused until the key expires.
+				if r := recover(); r != nil {
+					releaseIdempotencyKey(&record)
+					panic(r)
+				}
+			}()
+
+			handlerErr := next(c)
+			if handlerErr != nil {
+				c.Error(handlerErr)
+			}
+
+			status := c.Response().Status
+			if status >= http.StatusInternalServerError {
+				// Let the client retry requests that failed on our side.
+				releaseIdempotencyKey(&record)
+				return nil
+			}
+
+			if err := db.Model(&record).Updates(model.IdempotencyKey{
+				StatusCode:   status,
+				ContentType:  c.Response().Header().Get(echo.HeaderContentType),
+				ResponseBody: recorder.body.Bytes(),
+			}).Error; err != nil {
+				// The response is already sent. The key stays in progress,
+				// which refuses retries rather than repeating the request.
+				logrus.Errorf("Error storing response for Idempotency-Key %d: %v", record.IdempotencyKeyID, err)
+			}
+
+			return nil
+		}
+	}
+}
+
+func releaseIdempotencyKey(record *model.IdempotencyKey) {
+	if err := db.Delete(record).Error; err != nil {
+		logrus.Errorf("Error releasing Idempotency-Key %d: %v", record.IdempotencyKeyID, err)
+	}
+}
+
+func replayIdempotentResponse(c echo.Context, userID uint, key, requestHash string) error {
+	var existing model.IdempotencyKey
+	if err := db.Where("user_id = ? AND key = ?", userID, key).First(&existing).Error; err != nil {
+		return apperr.Internal(err, "Failed to process Idempotency-Key")
+	}
+
+	if existing.RequestHash != requestHash {
+		return apperr.ErrIdempotencyKeyReused
+	}
+
+	if existing.StatusCode == idempotencyInProgressCode {
+		return apperr.ErrIdempotencyKeyInProgress
+	}
+
+	c.Response().Header().Set(IdempotentReplayedHeader, "true")
+	return c.Blob(existing.StatusCode, existing.ContentType, existing.ResponseBody)
+}
+
+// hashRequest fingerprints the parts of a request that must match for a retry
+// to count as the same request.
+func hashRequest(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.Path))
+	h.Write([]byte{0})
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder copies everything written to the client into body.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -1,35 +1,44 @@
 package middleware
 
 import (
-    "github.com/labstack/echo/v4"
-    "github.com/golang-jwt/jwt/v5"
-    "os"
-    "net/http"
+	"mini-project/apperr"
+	"mini-project/model"
+	"mini-project/token"
+
+	"github.com/labstack/echo/v4"
 )
 
+var tokens *token.Service
+
+func SetTokenService(service *token.Service) {
+	tokens = service
+}
+
 func JWTMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
-    return func(c echo.Context) error {
-        tokenString := c.Request().Header.Get("Authorization")
-        if tokenString == "" {
-            return c.JSON(http.StatusUnauthorized, map[string]string{"message": "Invalid token credentials"})
-        }
-
-        token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-            secretKey := []byte(os.Getenv("JWT_SECRET_KEY"))
-            return secretKey, nil
-        })
-
-        if err != nil || !token.Valid {
-            return c.JSON(http.StatusUnauthorized, map[string]string{"message": "Invalid token credentials"})
-        }
-
-        userClaim, ok := token.Claims.(jwt.MapClaims)["user"].(string)
-        if !ok {
-            return c.JSON(http.StatusUnauthorized, map[string]string{"message": "Invalid token credentials"})
-        }
-
-        c.Set("user", userClaim)
-
-        return next(c)
-    }
-}
\ No newline at end of file
+	return func(c echo.Context) error {
+		tokenString := c.Request().Header.Get("Authorization")
+		if tokenString == "" {
+			return apperr.ErrUnauthenticated
+		}
+
+		claims, err := tokens.ParseAccessToken(tokenString)
+		if err != nil {
+			return apperr.ErrUnauthenticated
+		}
+
+		var session model.Session
+		if err := db.Where("session_id = ?", claims.SessionID).First(&session).Error; err != nil || session.RevokedAt != nil {
+			return apperr.ErrUnauthenticated
+		}
+
+		SetPrincipal(c, &Principal{
+			UserID:    claims.UserID,
+			Email:     claims.Email,
+			Roles:     claims.Roles,
+			SessionID: claims.SessionID,
+			MFA:       claims.MFA,
+		})
+
+		return next(c)
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import "github.com/labstack/echo/v4"
+
+const principalContextKey = "principal"
+
+// Principal is the authenticated caller, built from the JWT claims.
+type Principal struct {
+	UserID    uint
+	Email     string
+	Roles     []string
+	SessionID string
+	// MFA is true when the session was opened with a second factor.
+	MFA bool
+}
+
+// HasRole reports whether the principal holds at least one of the given roles.
+func (p *Principal) HasRole(roles ...string) bool {
+	for _, role := range p.Roles {
+		for _, r := range roles {
+			if role == r {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// CurrentPrincipal returns the principal stored by JWTMiddleware, or nil when
+// the request is not authenticated.
+func CurrentPrincipal(c echo.Context) *Principal {
+	principal, _ := c.Get(principalContextKey).(*Principal)
+	return principal
+}
+
+// SetPrincipal stores the authenticated caller for the rest of the request.
+func SetPrincipal(c echo.Context, principal *Principal) {
+	c.Set(principalContextKey, principal)
+}
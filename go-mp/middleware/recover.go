@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"fmt"
+	"mini-project/apperr"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Recover turns a panic in a handler into a 500 problem response, logged with
+// its stack trace by the error handler, instead of dropping the connection.
+func Recover(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) (err error) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			if r == http.ErrAbortHandler {
+				panic(r)
+			}
+
+			err = apperr.Internal(fmt.Errorf("panic: %v\n%s", r, debug.Stack()), "Internal server error")
+		}()
+
+		return next(c)
+	}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"mini-project/helper"
+
+	"github.com/labstack/echo/v4"
+)
+
+const maxRequestIDLength = 128
+
+// RequestID tags every response with an X-Request-ID header, reusing the one
+// sent by the client or a proxy when there is one. Error responses carry the
+// same value as their trace_id.
+func RequestID(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		requestID := c.Request().Header.Get(echo.HeaderXRequestID)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			var err error
+			if requestID, err = helper.GenerateRandomToken(16); err != nil {
+				return err
+			}
+		}
+
+		c.Response().Header().Set(echo.HeaderXRequestID, requestID)
+		return next(c)
+	}
+}
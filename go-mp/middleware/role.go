@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"mini-project/apperr"
+	"mini-project/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequireRoles only lets the request through when the authenticated user holds
+// at least one of the given roles. Users whose role has an MFA policy must
+// also have signed in with a second factor. It must run after JWTMiddleware.
+func RequireRoles(allowed ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal := CurrentPrincipal(c)
+			if principal == nil || !principal.HasRole(allowed...) {
+				return apperr.ErrForbidden
+			}
+
+			if !principal.MFA {
+				var required int64
+				err := db.Model(&model.MFAPolicy{}).
+					Where("role IN ? AND required = ?", principal.Roles, true).
+					Count(&required).Error
+				if err != nil {
+					return apperr.Internal(err, "Failed to check MFA policy")
+				}
+				if required > 0 {
+					return apperr.ErrMFARequired
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
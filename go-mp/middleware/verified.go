@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"mini-project/apperr"
+	"mini-project/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequireVerifiedEmail rejects requests from users who have not confirmed
+// their email address yet. It must run after JWTMiddleware.
+func RequireVerifiedEmail(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		principal := CurrentPrincipal(c)
+		if principal == nil {
+			return apperr.ErrUnauthenticated
+		}
+
+		user, err := repository.FindUser(db.Select("email_verified_at"), principal.UserID)
+		if repository.IsNotFound(err, repository.ResourceUser) {
+			return apperr.ErrUnauthenticated
+		}
+		if err != nil {
+			return apperr.Internal(err, "Failed to check email verification")
+		}
+
+		if user.EmailVerifiedAt == nil {
+			return apperr.ErrEmailNotVerified
+		}
+
+		return next(c)
+	}
+}
@@ -0,0 +1,33 @@
+package migrate
+
+import (
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// resetRentedAvailability makes equipment rentable again that the legacy
+// rent handler marked unavailable. That handler cleared availability on
+// every rental and nothing set it back, while bookings now block equipment
+// by date range instead. The legacy schema kept no record of who cleared the
+// flag, so equipment an admin switched off after it had been rented is
+// reset as well; the affected ids are logged so those can be switched off
+// again. The step only runs while the legacy rental_date column still
+// exists, as it is dropped by convertRentalDates.
+func resetRentedAvailability(tx *gorm.DB) error {
+	if !tx.Migrator().HasColumn("rental_histories", "rental_date") {
+		return nil
+	}
+
+	var ids []uint
+	if err := tx.Raw(`UPDATE equipment SET availability = true
+		WHERE availability = false
+		AND EXISTS (SELECT 1 FROM rental_histories WHERE rental_histories.equipment_id = equipment.equipment_id)
+		RETURNING equipment_id`).Scan(&ids).Error; err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		logrus.Infof("Marked rented equipment %v available again", ids)
+	}
+
+	return nil
+}
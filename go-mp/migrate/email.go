@@ -0,0 +1,42 @@
+package migrate
+
+import (
+	"fmt"
+	"mini-project/model"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// checkDuplicateEmails refuses to continue when the case-insensitive email
+// index is still missing and existing users would violate it. Which account
+// to keep is a decision for an operator, so the conflicting users are listed
+// instead of merged.
+func checkDuplicateEmails(tx *gorm.DB) error {
+	if !tx.Migrator().HasTable(&model.User{}) || tx.Migrator().HasIndex(&model.User{}, "idx_users_email_lower") {
+		return nil
+	}
+
+	var duplicates []struct {
+		Email   string
+		UserIDs string
+	}
+	if err := tx.Table("users").
+		Select("lower(email) AS email, string_agg(user_id::text, ',' ORDER BY user_id) AS user_ids").
+		Group("lower(email)").
+		Having("count(*) > 1").
+		Order("lower(email)").
+		Find(&duplicates).Error; err != nil {
+		return err
+	}
+	if len(duplicates) == 0 {
+		return nil
+	}
+
+	conflicts := make([]string, len(duplicates))
+	for i, d := range duplicates {
+		conflicts[i] = fmt.Sprintf("%s (users %s)", d.Email, d.UserIDs)
+	}
+
+	return fmt.Errorf("emails that differ only in case must be resolved before idx_users_email_lower can be created: %s", strings.Join(conflicts, "; "))
+}
@@ -0,0 +1,67 @@
+// Package migrate brings the database schema up to date at startup.
+//
+// AutoMigrate only adds tables, columns and indexes. Changes that have to
+// move existing data first run as steps before it; each step inspects the
+// live schema and does nothing on a fresh or already migrated database.
+// Everything runs in one transaction, so a failed step leaves the schema as
+// it was.
+package migrate
+
+import (
+	"fmt"
+	"mini-project/model"
+
+	"gorm.io/gorm"
+)
+
+type step struct {
+	name string
+	run  func(tx *gorm.DB) error
+}
+
+var steps = []step{
+	{"reset availability cleared by rentals", resetRentedAvailability},
+	{"convert rental date strings", convertRentalDates},
+	{"normalize legacy rental statuses", normalizeRentalStatuses},
+	{"convert rental costs to cents", convertRentalCostsToCents},
+	{"carry deposit amounts into the ledger", openingBalancesFromDeposits},
+	{"check for duplicate emails", checkDuplicateEmails},
+	{"redact sent outbox bodies", redactSentOutboxBodies},
+}
+
+var models = []interface{}{
+	&model.User{},
+	&model.Equipment{},
+	&model.RentalHistory{},
+	&model.LedgerAccount{},
+	&model.JournalEntry{},
+	&model.LedgerPosting{},
+	&model.IdempotencyKey{},
+	&model.OutboxMessage{},
+	&model.Session{},
+	&model.RefreshToken{},
+	&model.PasswordResetToken{},
+	&model.RecoveryCode{},
+	&model.MFAPolicy{},
+	&model.LoginAttempt{},
+	&model.LoginLockout{},
+	&model.AccountUnlockToken{},
+	&model.AuditLog{},
+}
+
+// Run applies the data migration steps and then AutoMigrates every model.
+func Run(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, s := range steps {
+			if err := s.run(tx); err != nil {
+				return fmt.Errorf("%s: %w", s.name, err)
+			}
+		}
+
+		if err := tx.AutoMigrate(models...); err != nil {
+			return fmt.Errorf("auto migrate: %w", err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,105 @@
+package migrate_test
+
+import (
+	"mini-project/migrate"
+	"mini-project/model"
+	"mini-project/testdb"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// legacySchema is the schema the application created before any migration
+// steps existed.
+var legacySchema = []string{
+	`CREATE TABLE users (user_id bigserial PRIMARY KEY, email text NOT NULL, password text NOT NULL, deposit_amount decimal)`,
+	`CREATE TABLE equipment (equipment_id bigserial PRIMARY KEY, name text NOT NULL, availability boolean NOT NULL, rental_costs decimal NOT NULL, category text NOT NULL)`,
+	`CREATE TABLE rental_histories (rental_history_id bigserial PRIMARY KEY, user_id bigint NOT NULL, equipment_id bigint NOT NULL, rental_date text NOT NULL, return_date text, rental_status text NOT NULL)`,
+}
+
+// openLegacy recreates the public schema of the test database with the
+// legacy tables.
+func openLegacy(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db := testdb.Connect(t)
+	for _, stmt := range append([]string{"DROP SCHEMA public CASCADE", "CREATE SCHEMA public"}, legacySchema...) {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("create legacy schema: %v", err)
+		}
+	}
+
+	return db
+}
+
+func TestRunMapsLegacyRentalStatuses(t *testing.T) {
+	db := openLegacy(t)
+
+	for _, stmt := range []string{
+		`INSERT INTO users (email, password, deposit_amount) VALUES ('renter@example.com', 'x', 10.5)`,
+		`INSERT INTO equipment (name, availability, rental_costs, category) VALUES ('Drill', true, 5, 'tools')`,
+		`INSERT INTO rental_histories (user_id, equipment_id, rental_date, return_date, rental_status) VALUES
+			(1, 1, '2020-01-01', '2020-01-03', 'rented'),
+			(1, 1, '2999-01-01', '2999-01-03', 'Active'),
+			(1, 1, '2020-02-01', '2020-02-03', 'cancelled')`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("seed legacy data: %v", err)
+		}
+	}
+
+	if err := migrate.Run(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	want := map[uint]string{
+		1: model.RentalStatusReturned,
+		2: model.RentalStatusConfirmed,
+		3: model.RentalStatusCancelled,
+	}
+	var rentals []model.RentalHistory
+	if err := db.Order("rental_history_id").Find(&rentals).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(rentals) != len(want) {
+		t.Fatalf("got %d rentals, want %d", len(rentals), len(want))
+	}
+	for _, rental := range rentals {
+		if rental.RentalStatus != want[rental.RentalHistoryID] {
+			t.Errorf("rental %d has status %q, want %q", rental.RentalHistoryID, rental.RentalStatus, want[rental.RentalHistoryID])
+		}
+	}
+}
+
+func TestRunResetsAvailabilityClearedByRentals(t *testing.T) {
+	db := openLegacy(t)
+
+	for _, stmt := range []string{
+		`INSERT INTO users (email, password) VALUES ('renter@example.com', 'x')`,
+		`INSERT INTO equipment (name, availability, rental_costs, category) VALUES
+			('Drill', false, 5, 'tools'),
+			('Saw', false, 5, 'tools'),
+			('Ladder', true, 5, 'tools')`,
+		`INSERT INTO rental_histories (user_id, equipment_id, rental_date, return_date, rental_status) VALUES
+			(1, 1, '2020-01-01', '2020-01-03', 'rented')`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("seed legacy data: %v", err)
+		}
+	}
+
+	if err := migrate.Run(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	want := map[uint]bool{1: true, 2: false, 3: true}
+	var equipment []model.Equipment
+	if err := db.Order("equipment_id").Find(&equipment).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range equipment {
+		if e.Availability != want[e.EquipmentID] {
+			t.Errorf("equipment %d availability = %v, want %v", e.EquipmentID, e.Availability, want[e.EquipmentID])
+		}
+	}
+}
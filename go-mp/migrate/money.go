@@ -0,0 +1,56 @@
+package migrate
+
+import (
+	"mini-project/ledger"
+	"mini-project/model"
+
+	"gorm.io/gorm"
+)
+
+// convertRentalCostsToCents turns equipment.rental_costs from a dollar
+// amount stored as a floating point number into whole cents.
+func convertRentalCostsToCents(tx *gorm.DB) error {
+	var dataType string
+	if err := tx.Raw("SELECT data_type FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = 'equipment' AND column_name = 'rental_costs'").
+		Scan(&dataType).Error; err != nil {
+		return err
+	}
+	if dataType != "double precision" && dataType != "real" && dataType != "numeric" {
+		return nil
+	}
+
+	return tx.Exec("ALTER TABLE equipment ALTER COLUMN rental_costs TYPE bigint USING round(rental_costs * 100)").Error
+}
+
+// openingBalancesFromDeposits moves the users.deposit_amount dollar balance
+// into the ledger as an opening balance entry per user, then drops the
+// column so the step runs only once.
+func openingBalancesFromDeposits(tx *gorm.DB) error {
+	if !tx.Migrator().HasColumn("users", "deposit_amount") {
+		return nil
+	}
+
+	if err := tx.AutoMigrate(&model.LedgerAccount{}, &model.JournalEntry{}, &model.LedgerPosting{}); err != nil {
+		return err
+	}
+
+	var rows []struct {
+		UserID uint
+		Amount int64
+	}
+	if err := tx.Table("users").
+		Select("user_id, round(deposit_amount * 100)::bigint AS amount").
+		Where("round(deposit_amount * 100) <> 0").
+		Order("user_id").
+		Find(&rows).Error; err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if _, err := ledger.OpeningBalance(tx, row.UserID, row.Amount); err != nil {
+			return err
+		}
+	}
+
+	return tx.Exec("ALTER TABLE users DROP COLUMN deposit_amount").Error
+}
@@ -0,0 +1,19 @@
+package migrate
+
+import (
+	"mini-project/model"
+
+	"gorm.io/gorm"
+)
+
+// redactSentOutboxBodies clears the bodies of mail sent before the worker
+// started doing so itself after delivery.
+func redactSentOutboxBodies(tx *gorm.DB) error {
+	if !tx.Migrator().HasTable(&model.OutboxMessage{}) {
+		return nil
+	}
+
+	return tx.Model(&model.OutboxMessage{}).
+		Where("status = ? AND (body <> '' OR html_body <> '')", model.OutboxStatusSent).
+		Updates(map[string]interface{}{"body": "", "html_body": ""}).Error
+}
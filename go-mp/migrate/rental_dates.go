@@ -0,0 +1,82 @@
+package migrate
+
+import (
+	"fmt"
+	"mini-project/helper"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// legacyDateLayouts are tried after helper.ParseDate for rental_date and
+// return_date values written before rentals were booked by date range.
+var legacyDateLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+}
+
+// convertRentalDates replaces the free-form rental_date and return_date
+// strings with the start_date and end_date timestamps. A rental without a
+// return date is treated as a one-day booking. Rows whose dates cannot be
+// parsed abort the migration and are listed, so they can be fixed by hand.
+func convertRentalDates(tx *gorm.DB) error {
+	if !tx.Migrator().HasColumn("rental_histories", "rental_date") {
+		return nil
+	}
+
+	if err := tx.Exec("ALTER TABLE rental_histories ADD COLUMN IF NOT EXISTS start_date timestamptz, ADD COLUMN IF NOT EXISTS end_date timestamptz").Error; err != nil {
+		return err
+	}
+
+	var rows []struct {
+		RentalHistoryID uint
+		RentalDate      string
+		ReturnDate      *string
+	}
+	if err := tx.Table("rental_histories").Select("rental_history_id, rental_date, return_date").Find(&rows).Error; err != nil {
+		return err
+	}
+
+	var invalid []string
+	for _, row := range rows {
+		start, err := parseLegacyDate(row.RentalDate)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%d (rental_date %q)", row.RentalHistoryID, row.RentalDate))
+			continue
+		}
+
+		end := start.AddDate(0, 0, 1)
+		if row.ReturnDate != nil && strings.TrimSpace(*row.ReturnDate) != "" {
+			if end, err = parseLegacyDate(*row.ReturnDate); err != nil {
+				invalid = append(invalid, fmt.Sprintf("%d (return_date %q)", row.RentalHistoryID, *row.ReturnDate))
+				continue
+			}
+		}
+
+		if err := tx.Table("rental_histories").Where("rental_history_id = ?", row.RentalHistoryID).
+			Updates(map[string]interface{}{"start_date": start, "end_date": end}).Error; err != nil {
+			return err
+		}
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("unparseable dates in rental histories %s", strings.Join(invalid, ", "))
+	}
+
+	return tx.Exec("ALTER TABLE rental_histories ALTER COLUMN start_date SET NOT NULL, ALTER COLUMN end_date SET NOT NULL, DROP COLUMN rental_date, DROP COLUMN return_date").Error
+}
+
+func parseLegacyDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := helper.ParseDate(value); err == nil {
+		return t, nil
+	}
+
+	for _, layout := range legacyDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognised date %q", value)
+}
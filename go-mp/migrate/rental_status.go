@@ -0,0 +1,20 @@
+package migrate
+
+import (
+	"mini-project/model"
+
+	"gorm.io/gorm"
+)
+
+// normalizeRentalStatuses maps the free-form statuses rentals were created
+// with before the lifecycle existed onto it. A rental whose end date has
+// passed is taken as returned and any other as confirmed, the state a
+// booking with a held deposit is in before checkout.
+func normalizeRentalStatuses(tx *gorm.DB) error {
+	if !tx.Migrator().HasTable("rental_histories") {
+		return nil
+	}
+
+	return tx.Exec("UPDATE rental_histories SET rental_status = CASE WHEN end_date < now() THEN ? ELSE ? END WHERE rental_status IS NULL OR rental_status NOT IN ?",
+		model.RentalStatusReturned, model.RentalStatusConfirmed, model.RentalStatuses).Error
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+const (
+	AuditLoginSucceeded  = "login_succeeded"
+	AuditLoginFailed     = "login_failed"
+	AuditLoginThrottled  = "login_throttled"
+	AuditAccountLocked   = "account_locked"
+	AuditAccountUnlocked = "account_unlocked"
+)
+
+// AuditLog is an append-only record of security relevant events. UserID is
+// nil when the event concerns an email address without an account.
+type AuditLog struct {
+	AuditLogID uint   `gorm:"primaryKey"`
+	Event      string `gorm:"not null;index"`
+	UserID     *uint  `gorm:"index"`
+	Email      string `gorm:"not null"`
+	IPAddress  string `gorm:"not null"`
+	Detail     string
+	CreatedAt  time.Time `gorm:"index"`
+}
+
+// AuditLogPage is one page of the audit log.
+type AuditLogPage struct {
+	Data []AuditLog `json:"data"`
+	// NextCursor fetches the following page; it is omitted on the last page.
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJzIjoiLWF1ZGl0X2xvZ19pZCIsInYiOjIwLCJpZCI6MjB9"`
+	// Next is the URL of the following page.
+	Next string `json:"next,omitempty" example:"/admin/audit-logs?cursor=eyJzIjoiLWF1ZGl0X2xvZ19pZCIsInYiOjIwLCJpZCI6MjB9"`
+	// Total counts every match across all pages, if include_total was set.
+	Total *int64 `json:"total,omitempty" example:"1234"`
+}
@@ -1,23 +1,45 @@
 package model
 
+import "gorm.io/gorm"
+
+// Equipment is an item that can be rented. Availability is switched off by
+// admins to take the item out of service, which stops new bookings; whether
+// it is free on given dates follows from its rentals. Version is incremented
+// by every update and serves as the ETag. Deleted equipment is kept, hidden
+// from queries, until the purge job removes it.
 type Equipment struct {
-    EquipmentID   uint    `gorm:"primaryKey"`
-    Name          string  `gorm:"not null"`
-    Availability  bool    `gorm:"not null"`
-    RentalCosts   float64 `gorm:"not null"`
-    Category      string  `gorm:"not null"`
+	EquipmentID  uint           `gorm:"primaryKey"`
+	Name         string         `gorm:"not null"`
+	Availability bool           `gorm:"not null"`
+	RentalCosts  int64          `gorm:"not null;index"` // in cents
+	Category     string         `gorm:"not null;index"`
+	Version      uint           `gorm:"not null;default:1"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" swaggertype:"string" format:"date-time"`
 }
 
 type CreateEquipmentRequestBody struct {
-    Name         string  `json:"name"`
-    Availability bool    `json:"availability"`
-    RentalCosts  float64 `json:"rental_costs"`
-    Category     string  `json:"category"`
+	Name         string `json:"name" validate:"required,max=100"`
+	Availability bool   `json:"availability"`
+	RentalCosts  int64  `json:"rental_costs" validate:"gt=0"`
+	Category     string `json:"category" validate:"required,max=50"`
 }
 
+// UpdateEquipmentRequestBody is the full set of editable fields, sent whole
+// by PUT and patched by PATCH.
 type UpdateEquipmentRequestBody struct {
-    Name         string  `json:"name"`
-    Availability bool    `json:"availability"`
-    RentalCosts  float64 `json:"rental_costs"`
-    Category     string  `json:"category"`
-}
\ No newline at end of file
+	Name         string `json:"name" validate:"required,max=100"`
+	Availability bool   `json:"availability"`
+	RentalCosts  int64  `json:"rental_costs" validate:"gt=0"`
+	Category     string `json:"category" validate:"required,max=50"`
+}
+
+// EquipmentPage is one page of an equipment listing.
+type EquipmentPage struct {
+	Data []Equipment `json:"data"`
+	// NextCursor fetches the following page; it is omitted on the last page.
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJzIjoiIiwidiI6MjAsImlkIjoyMH0"`
+	// Next is the URL of the following page.
+	Next string `json:"next,omitempty" example:"/equipment?cursor=eyJzIjoiIiwidiI6MjAsImlkIjoyMH0"`
+	// Total counts every match across all pages, if include_total was set.
+	Total *int64 `json:"total,omitempty" example:"1234"`
+}
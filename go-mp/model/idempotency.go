@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// IdempotencyKey stores the first response sent for an Idempotency-Key so that
+// retries of the same request can be answered without running it again. A
+// StatusCode of zero means the original request is still in progress.
+type IdempotencyKey struct {
+	IdempotencyKeyID uint   `gorm:"primaryKey"`
+	UserID           uint   `gorm:"not null;uniqueIndex:idx_idempotency_keys_user_key"`
+	Key              string `gorm:"not null;uniqueIndex:idx_idempotency_keys_user_key"`
+	RequestHash      string `gorm:"not null"`
+	StatusCode       int    `gorm:"not null;default:0"`
+	ContentType      string
+	ResponseBody     []byte
+	CreatedAt        time.Time
+	ExpiresAt        time.Time `gorm:"not null;index"`
+}
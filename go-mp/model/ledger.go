@@ -0,0 +1,82 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Ledger amounts are signed integers in minor currency units (cents). A
+// positive posting increases the balance of its account, and the postings of
+// every journal entry sum to zero.
+
+const (
+	AccountTypeUserWallet    = "user_wallet"
+	AccountTypeExternal      = "external"
+	AccountTypeDepositHolds  = "deposit_holds"
+	AccountTypeRentalRevenue = "rental_revenue"
+	AccountTypeFeeRevenue    = "fee_revenue"
+)
+
+const (
+	EntryTypeOpeningBalance = "opening_balance"
+	EntryTypeTopUp          = "top_up"
+	EntryTypeRentalHold     = "rental_hold"
+	EntryTypeRentalCharge   = "rental_charge"
+	EntryTypeRentalRefund   = "rental_refund"
+	EntryTypeLateReturnFee  = "late_return_fee"
+)
+
+var ErrLedgerImmutable = errors.New("ledger records are immutable")
+
+type LedgerAccount struct {
+	LedgerAccountID uint   `gorm:"primaryKey"`
+	Code            string `gorm:"not null;uniqueIndex"`
+	Type            string `gorm:"not null"`
+	UserID          *uint  `gorm:"uniqueIndex"`
+	CreatedAt       time.Time
+}
+
+type JournalEntry struct {
+	JournalEntryID uint   `gorm:"primaryKey"`
+	EntryType      string `gorm:"not null;index"`
+	Description    string `gorm:"not null"`
+	// RentalHistoryID is a plain reference without a foreign key, so entries
+	// survive the purge of the rental they belong to.
+	RentalHistoryID *uint `gorm:"index"`
+	CreatedAt       time.Time
+	Postings        []LedgerPosting `gorm:"foreignKey:JournalEntryID"`
+}
+
+type LedgerPosting struct {
+	LedgerPostingID uint  `gorm:"primaryKey"`
+	JournalEntryID  uint  `gorm:"not null;index"`
+	LedgerAccountID uint  `gorm:"not null;index"`
+	Amount          int64 `gorm:"not null"`
+}
+
+func (JournalEntry) BeforeUpdate(*gorm.DB) error  { return ErrLedgerImmutable }
+func (JournalEntry) BeforeDelete(*gorm.DB) error  { return ErrLedgerImmutable }
+func (LedgerPosting) BeforeUpdate(*gorm.DB) error { return ErrLedgerImmutable }
+func (LedgerPosting) BeforeDelete(*gorm.DB) error { return ErrLedgerImmutable }
+
+// WalletTransaction is one journal entry as seen from a user's wallet.
+type WalletTransaction struct {
+	JournalEntryID  uint      `json:"journal_entry_id"`
+	EntryType       string    `json:"entry_type"`
+	Description     string    `json:"description"`
+	RentalHistoryID *uint     `json:"rental_history_id,omitempty"`
+	Amount          int64     `json:"amount"`
+	BalanceAfter    int64     `json:"balance_after"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// WalletBalance is the state of a user's wallet in cents. Balance goes
+// negative when a late return fee exceeds it; Outstanding is then the amount
+// to top up before the user can book again.
+type WalletBalance struct {
+	Balance     int64 `json:"balance"`
+	Held        int64 `json:"held"`
+	Outstanding int64 `json:"outstanding"`
+}
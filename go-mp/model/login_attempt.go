@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// LoginAttempt records one password check, successful or not. Failures are
+// counted per email and per client IP to throttle guessing.
+type LoginAttempt struct {
+	LoginAttemptID uint      `gorm:"primaryKey"`
+	Email          string    `gorm:"not null;index:idx_login_attempts_email_created_at,priority:1"`
+	IPAddress      string    `gorm:"not null;index:idx_login_attempts_ip_address_created_at,priority:1"`
+	Succeeded      bool      `gorm:"not null"`
+	CreatedAt      time.Time `gorm:"index:idx_login_attempts_email_created_at,priority:2;index:idx_login_attempts_ip_address_created_at,priority:2"`
+}
+
+// LoginLockout holds the lockout state of an email address. It is keyed by
+// email rather than user so unknown addresses are throttled the same way as
+// registered ones. Failures before ResetAt no longer count.
+type LoginLockout struct {
+	Email       string `gorm:"primaryKey"`
+	LockedUntil *time.Time
+	ResetAt     time.Time `gorm:"not null"`
+	UpdatedAt   time.Time
+}
+
+// AccountUnlockToken is the single-use token in the email sent when an
+// account is locked, stored as a SHA-256 hash.
+type AccountUnlockToken struct {
+	AccountUnlockTokenID uint      `gorm:"primaryKey"`
+	UserID               uint      `gorm:"not null;index"`
+	TokenHash            string    `gorm:"not null;uniqueIndex"`
+	ExpiresAt            time.Time `gorm:"not null"`
+	UsedAt               *time.Time
+	CreatedAt            time.Time
+}
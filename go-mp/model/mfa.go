@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// RecoveryCode lets a user finish an MFA login without their authenticator.
+// Codes are stored as SHA-256 hashes and can be used once.
+type RecoveryCode struct {
+	RecoveryCodeID uint   `gorm:"primaryKey"`
+	UserID         uint   `gorm:"not null;index"`
+	CodeHash       string `gorm:"not null;uniqueIndex"`
+	UsedAt         *time.Time
+	CreatedAt      time.Time
+}
+
+// MFAPolicy records whether users holding Role must sign in with a second
+// factor before they can use role-protected endpoints.
+type MFAPolicy struct {
+	Role      string `gorm:"primaryKey"`
+	Required  bool   `gorm:"not null"`
+	UpdatedAt time.Time
+}
+
+type ConfirmMFARequestBody struct {
+	Code string `json:"code" validate:"required,max=10"`
+}
+
+// MFACodeRequestBody carries a second factor: either an authenticator code or
+// a recovery code.
+type MFACodeRequestBody struct {
+	Code         string `json:"code" validate:"required_without=RecoveryCode,max=10"`
+	RecoveryCode string `json:"recovery_code,omitempty" validate:"max=32"`
+}
+
+type MFALoginRequestBody struct {
+	MFAToken     string `json:"mfa_token" validate:"required"`
+	Code         string `json:"code" validate:"required_without=RecoveryCode,max=10"`
+	RecoveryCode string `json:"recovery_code,omitempty" validate:"max=32"`
+}
+
+type UpdateMFAPolicyRequestBody struct {
+	Required *bool `json:"required" validate:"required"`
+}
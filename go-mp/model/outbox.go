@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusSent    = "sent"
+	OutboxStatusDead    = "dead"
+)
+
+// OutboxMessage is an email written in the same transaction as the business
+// change that triggered it and delivered later by the outbox worker.
+//
+// Bodies can carry one-time links for password resets, account unlocks and
+// email verification, so they are never serialized and are cleared once the
+// message has been sent.
+type OutboxMessage struct {
+	OutboxMessageID uint      `gorm:"primaryKey"`
+	Recipient       string    `gorm:"not null"`
+	Subject         string    `gorm:"not null"`
+	Body            string    `gorm:"type:text;not null" json:"-"`
+	HTMLBody        string    `gorm:"type:text" json:"-"`
+	Status          string    `gorm:"not null;default:pending;index:idx_outbox_messages_status_next_attempt"`
+	Attempts        int       `gorm:"not null;default:0"`
+	NextAttemptAt   time.Time `gorm:"not null;index:idx_outbox_messages_status_next_attempt"`
+	// LockedUntil is the end of the lease a worker took when it claimed the
+	// message. Other workers leave the message alone until it expires.
+	LockedUntil *time.Time
+	LastError   string
+	SentAt      *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// OutboxMessagePage is one page of an outbox listing.
+type OutboxMessagePage struct {
+	Data []OutboxMessage `json:"data"`
+	// NextCursor fetches the following page; it is omitted on the last page.
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJzIjoiLW91dGJveF9tZXNzYWdlX2lkIiwidiI6MjAsImlkIjoyMH0"`
+	// Next is the URL of the following page.
+	Next string `json:"next,omitempty" example:"/admin/outbox?cursor=eyJzIjoiLW91dGJveF9tZXNzYWdlX2lkIiwidiI6MjAsImlkIjoyMH0"`
+	// Total counts every match across all pages, if include_total was set.
+	Total *int64 `json:"total,omitempty" example:"1234"`
+}
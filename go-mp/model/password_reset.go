@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// PasswordResetToken is a single-use reset link token, stored as a SHA-256 hash.
+type PasswordResetToken struct {
+	PasswordResetTokenID uint      `gorm:"primaryKey"`
+	UserID               uint      `gorm:"not null;index"`
+	TokenHash            string    `gorm:"not null;uniqueIndex"`
+	ExpiresAt            time.Time `gorm:"not null"`
+	UsedAt               *time.Time
+	CreatedAt            time.Time
+}
+
+type ForgotPasswordRequestBody struct {
+	Email string `json:"email" validate:"required"`
+}
+
+type ResetPasswordRequestBody struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,password"`
+}
@@ -1,26 +1,136 @@
 package model
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	RentalStatusRequested  = "requested"
+	RentalStatusConfirmed  = "confirmed"
+	RentalStatusCheckedOut = "checked_out"
+	RentalStatusReturned   = "returned"
+	RentalStatusOverdue    = "overdue"
+	RentalStatusCancelled  = "cancelled"
+)
+
+// rentalTransitions lists, for every status, the statuses a rental may move to
+// next. Returned and cancelled are terminal.
+var rentalTransitions = map[string][]string{
+	RentalStatusRequested:  {RentalStatusConfirmed, RentalStatusCancelled},
+	RentalStatusConfirmed:  {RentalStatusCheckedOut, RentalStatusCancelled},
+	RentalStatusCheckedOut: {RentalStatusReturned, RentalStatusOverdue},
+	RentalStatusOverdue:    {RentalStatusReturned},
+}
+
+// RentalStatuses lists every rental status.
+var RentalStatuses = []string{
+	RentalStatusRequested,
+	RentalStatusConfirmed,
+	RentalStatusCheckedOut,
+	RentalStatusReturned,
+	RentalStatusOverdue,
+	RentalStatusCancelled,
+}
+
+// InactiveRentalStatuses are the statuses whose date range no longer blocks
+// the equipment for other bookings.
+var InactiveRentalStatuses = []string{RentalStatusCancelled, RentalStatusReturned}
+
+// EditableRentalStatuses are the statuses in which a rental's dates can still
+// be changed, before the equipment has been handed over.
+var EditableRentalStatuses = []string{RentalStatusRequested, RentalStatusConfirmed}
+
+// CanTransitionRental reports whether a rental in status from may move to status to.
+func CanTransitionRental(from, to string) bool {
+	for _, next := range rentalTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RentalHistory is a booking of an equipment item by a user. Version is
+// incremented by every update and serves as the ETag. Deleted rentals are
+// kept, hidden from queries, until the purge job removes them. User and
+// Equipment are only loaded by listings and single reads.
 type RentalHistory struct {
-    RentalHistoryID uint   `gorm:"primaryKey"`
-    UserID          uint   `gorm:"not null"`
-    EquipmentID     uint   `gorm:"not null"`
-    RentalDate      string `gorm:"not null"`
-    ReturnDate      string
-    RentalStatus    string `gorm:"not null"`
+	RentalHistoryID uint      `gorm:"primaryKey"`
+	UserID          uint      `gorm:"not null;index"`
+	EquipmentID     uint      `gorm:"not null;index"`
+	StartDate       time.Time `gorm:"not null;index"`
+	EndDate         time.Time `gorm:"not null"`
+	RentalStatus    string    `gorm:"not null;default:requested"`
+	DepositHold     int64     `gorm:"not null;default:0"`
+	AmountCharged   int64     `gorm:"not null;default:0"`
+	CheckedOutAt    *time.Time
+	ReturnedAt      *time.Time
+	Version         uint              `gorm:"not null;default:1"`
+	DeletedAt       gorm.DeletedAt    `gorm:"index" swaggertype:"string" format:"date-time"`
+	User            *UserSummary      `gorm:"foreignKey:UserID;-:migration" json:",omitempty"`
+	Equipment       *EquipmentSummary `gorm:"foreignKey:EquipmentID;-:migration" json:",omitempty"`
+}
+
+// UserSummary is the part of a user embedded in rental listings. Its key is
+// named ID rather than UserID so that GORM sees RentalHistory.User as a
+// belongs-to relation.
+type UserSummary struct {
+	ID    uint   `gorm:"primaryKey;column:user_id" json:"user_id"`
+	Email string `json:"email"`
+}
+
+func (UserSummary) TableName() string {
+	return "users"
+}
+
+// EquipmentSummary is the part of an equipment item embedded in rental
+// listings. Its key is named ID for the same reason as UserSummary's.
+type EquipmentSummary struct {
+	ID       uint   `gorm:"primaryKey;column:equipment_id" json:"equipment_id"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+}
+
+func (EquipmentSummary) TableName() string {
+	return "equipment"
+}
+
+// RentalHistoryPage is one page of a rental listing.
+type RentalHistoryPage struct {
+	Data []RentalHistory `json:"data"`
+	// NextCursor fetches the following page; it is omitted on the last page.
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJzIjoiIiwidiI6MjAsImlkIjoyMH0"`
+	// Next is the URL of the following page.
+	Next string `json:"next,omitempty" example:"/rental?cursor=eyJzIjoiIiwidiI6MjAsImlkIjoyMH0"`
+	// Total counts every match across all pages, if include_total was set.
+	Total *int64 `json:"total,omitempty" example:"1234"`
 }
 
 type CreateRentalHistoryRequestBody struct {
-    UserID       uint   `json:"user_id"`
-    EquipmentID  uint   `json:"equipment_id"`
-    RentalDate   string `json:"rental_date"`
-    ReturnDate   string `json:"return_date"`
-    RentalStatus string `json:"rental_status"`
+	EquipmentID uint      `json:"equipment_id" validate:"required"`
+	StartDate   time.Time `json:"start_date" validate:"required,not_past"`
+	EndDate     time.Time `json:"end_date" validate:"required,gtfield=StartDate"`
 }
 
 type UpdateRentalHistoryRequestBody struct {
-    UserID       uint   `json:"user_id"`
-    EquipmentID  uint   `json:"equipment_id"`
-    RentalDate   string `json:"rental_date"`
-    ReturnDate   string `json:"return_date"`
-    RentalStatus string `json:"rental_status"`
-}
\ No newline at end of file
+	UserID      uint      `json:"user_id" validate:"required"`
+	EquipmentID uint      `json:"equipment_id" validate:"required"`
+	StartDate   time.Time `json:"start_date" validate:"required"`
+	EndDate     time.Time `json:"end_date" validate:"required,gtfield=StartDate"`
+}
+
+type EquipmentAvailability struct {
+	EquipmentID uint              `json:"equipment_id"`
+	StartDate   time.Time         `json:"start_date"`
+	EndDate     time.Time         `json:"end_date"`
+	Available   bool              `json:"available"`
+	BookedSlots []BookedDateRange `json:"booked_slots"`
+}
+
+type BookedDateRange struct {
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// Session groups the refresh tokens issued from one login. Access tokens carry
+// the session ID in their sid claim, so revoking the session also rejects
+// every access token issued for it.
+type Session struct {
+	SessionID string `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	// MFA is set when the login that opened the session passed a second
+	// factor. Refreshed access tokens inherit it.
+	MFA       bool `gorm:"not null;default:false"`
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+// RefreshToken is stored as a SHA-256 hash. A token can be exchanged once;
+// presenting it again is treated as theft and revokes the whole session.
+type RefreshToken struct {
+	RefreshTokenID uint      `gorm:"primaryKey"`
+	SessionID      string    `gorm:"not null;index"`
+	TokenHash      string    `gorm:"not null;uniqueIndex"`
+	ExpiresAt      time.Time `gorm:"not null"`
+	UsedAt         *time.Time
+	CreatedAt      time.Time
+}
+
+type RefreshTokenRequestBody struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
@@ -1,17 +1,49 @@
 package model
 
+import "time"
+
+const (
+	RoleAdmin  = "admin"
+	RoleRenter = "renter"
+)
+
+// User emails are stored lowercase. The unique index is on lower(email), so
+// it also holds for rows written before emails were normalized.
 type User struct {
-    UserID        uint    `gorm:"primaryKey"`
-    Email         string  `gorm:"not null"`
-    Password      string  `gorm:"not null"`
-    DepositAmount float64
+	UserID          uint   `gorm:"primaryKey"`
+	Email           string `gorm:"not null;uniqueIndex:idx_users_email_lower,expression:lower(email)"`
+	Password        string `gorm:"not null" json:"-"`
+	Role            string `gorm:"not null;default:renter"`
+	Locale          string `gorm:"not null;default:en"`
+	EmailVerifiedAt *time.Time
+	MFAEnabledAt    *time.Time
+	// TOTPSecret is set when enrollment starts and only used for logins once
+	// MFAEnabledAt is set.
+	TOTPSecret string `json:"-"`
+	// LastTOTPStep is the time step of the last accepted code, so a code
+	// cannot be replayed within its validity window.
+	LastTOTPStep int64 `gorm:"not null;default:0" json:"-"`
 }
 
 type RegisterRequestBody struct {
-    Email    string `json:"email"`
-    Password string `json:"password"`
+	Email    string `json:"email" validate:"required,email,max=254"`
+	Password string `json:"password" validate:"required,password"`
+	Locale   string `json:"locale,omitempty"`
+}
+
+type LoginRequestBody struct {
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
 }
 
 type TopUpRequestBody struct {
-	DepositAmount float64 `json:"deposit_amount"`
-}
\ No newline at end of file
+	DepositAmount int64 `json:"deposit_amount" validate:"gt=0"`
+}
+
+type UpdateUserRoleRequestBody struct {
+	Role string `json:"role" validate:"required,oneof=admin renter"`
+}
+
+func IsValidRole(role string) bool {
+	return role == RoleAdmin || role == RoleRenter
+}
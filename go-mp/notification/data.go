@@ -0,0 +1,55 @@
+package notification
+
+import "time"
+
+type RegistrationData struct {
+	Email           string
+	VerificationURL string
+	ExpiresAt       time.Time
+}
+
+type EmailVerificationData struct {
+	Email           string
+	VerificationURL string
+	ExpiresAt       time.Time
+}
+
+type PasswordResetData struct {
+	Email     string
+	ResetURL  string
+	ExpiresAt time.Time
+}
+
+type AccountLockedData struct {
+	Email       string
+	LockedUntil time.Time
+	UnlockURL   string
+}
+
+type TopUpData struct {
+	Amount  int64
+	Balance int64
+}
+
+type RentalConfirmationData struct {
+	RentalID      uint
+	EquipmentName string
+	StartDate     time.Time
+	EndDate       time.Time
+	DepositHold   int64
+}
+
+type ReturnReceiptData struct {
+	RentalID      uint
+	EquipmentName string
+	ReturnedAt    time.Time
+	RentalCharge  int64
+	LateFee       int64
+	Total         int64
+}
+
+type OverdueReminderData struct {
+	RentalID      uint
+	EquipmentName string
+	EndDate       time.Time
+}
@@ -0,0 +1,215 @@
+// Package notification renders the notification emails from templates. Every
+// notification has a subject, a plain-text and an HTML template per locale.
+// The templates are embedded in the binary and can be overridden by files
+// with the same relative path in an operator-supplied directory. An override
+// that fails to parse or execute is skipped in favour of the embedded
+// template, so a broken override never fails the request that sends mail.
+package notification
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+	"mini-project/helper"
+	"mini-project/mailer"
+	"os"
+	"path"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	Registration       = "registration"
+	EmailVerification  = "email_verification"
+	PasswordReset      = "password_reset"
+	AccountLocked      = "account_locked"
+	TopUp              = "top_up"
+	RentalConfirmation = "rental_confirmation"
+	ReturnReceipt      = "return_receipt"
+	OverdueReminder    = "overdue_reminder"
+)
+
+const DefaultLocale = "en"
+
+//go:embed templates
+var embedded embed.FS
+
+var funcs = map[string]interface{}{
+	"money": helper.FormatMoney,
+	"date": func(t time.Time) string {
+		return t.Format("2 Jan 2006")
+	},
+	"time": func(t time.Time) string {
+		return t.UTC().Format("15:04 MST")
+	},
+}
+
+// Renderer renders notifications into mailer messages.
+type Renderer struct {
+	overrides fs.FS
+	embedded  fs.FS
+}
+
+// NewRenderer returns a renderer that prefers templates found in overrideDir
+// over the embedded ones. An empty overrideDir uses only the embedded set.
+// Templates are read on every render, so overrides take effect immediately.
+func NewRenderer(overrideDir string) *Renderer {
+	templates, _ := fs.Sub(embedded, "templates")
+
+	r := &Renderer{embedded: templates}
+	if overrideDir != "" {
+		r.overrides = os.DirFS(overrideDir)
+	}
+
+	return r
+}
+
+// CheckOverrides parses every template in the override directory and returns
+// an error for each one that is broken. Broken overrides are skipped when
+// rendering, so the errors are warnings for the operator.
+func (r *Renderer) CheckOverrides() []error {
+	if r.overrides == nil {
+		return nil
+	}
+
+	var errs []error
+	err := fs.WalkDir(r.overrides, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(p, ".tmpl") {
+			return nil
+		}
+
+		content, err := fs.ReadFile(r.overrides, p)
+		if err == nil {
+			_, err = compile(p, string(content))
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p, err))
+		}
+
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// IsSupportedLocale reports whether the embedded templates include the locale.
+func IsSupportedLocale(locale string) bool {
+	if locale == "" || strings.ContainsAny(locale, "/\\.") {
+		return false
+	}
+
+	info, err := fs.Stat(embedded, path.Join("templates", locale))
+	return err == nil && info.IsDir()
+}
+
+// Render builds the message for the named notification in the given locale,
+// falling back to DefaultLocale when the locale has no template.
+func (r *Renderer) Render(name, locale, to string, data interface{}) (mailer.Message, error) {
+	subject, err := r.render(name+".subject.tmpl", locale, data)
+	if err != nil {
+		return mailer.Message{}, err
+	}
+
+	body, err := r.render(name+".txt.tmpl", locale, data)
+	if err != nil {
+		return mailer.Message{}, err
+	}
+
+	htmlBody, err := r.render(name+".html.tmpl", locale, data)
+	if err != nil {
+		return mailer.Message{}, err
+	}
+
+	return mailer.Message{
+		To:       to,
+		Subject:  strings.TrimSpace(subject),
+		Body:     body,
+		HTMLBody: htmlBody,
+	}, nil
+}
+
+// render executes the first template found, checking the requested locale
+// before the default one and the override directory before the embedded
+// files. A broken override is logged and passed over.
+func (r *Renderer) render(file, locale string, data interface{}) (string, error) {
+	locales := []string{locale}
+	if locale != DefaultLocale {
+		locales = append(locales, DefaultLocale)
+	}
+
+	for _, l := range locales {
+		p := path.Join(l, file)
+
+		if r.overrides != nil {
+			out, err := execute(r.overrides, p, data)
+			if err == nil {
+				return out, nil
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				logrus.Warnf("Email template override %s is broken, using the embedded template: %v", p, err)
+			}
+		}
+
+		out, err := execute(r.embedded, p, data)
+		if !errors.Is(err, fs.ErrNotExist) {
+			return out, err
+		}
+	}
+
+	return "", fs.ErrNotExist
+}
+
+// execute renders the template at p in source.
+func execute(source fs.FS, p string, data interface{}) (string, error) {
+	content, err := fs.ReadFile(source, p)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := compile(p, string(content))
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+type template interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+// compile parses the template at p as HTML when its name ends in .html.tmpl
+// and as plain text otherwise.
+func compile(p, content string) (template, error) {
+	if strings.HasSuffix(p, ".html.tmpl") {
+		tmpl, err := htmltemplate.New(path.Base(p)).Funcs(funcs).Parse(content)
+		if err != nil {
+			return nil, err
+		}
+		return tmpl, nil
+	}
+
+	tmpl, err := texttemplate.New(path.Base(p)).Funcs(funcs).Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
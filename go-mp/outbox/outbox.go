@@ -0,0 +1,54 @@
+// Package outbox implements the transactional outbox for notification emails.
+// Messages are stored with Enqueue inside the caller's transaction and
+// delivered by a Worker with retries and exponential backoff.
+package outbox
+
+import (
+	"errors"
+	"mini-project/mailer"
+	"mini-project/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrNotRedrivable = errors.New("only dead messages can be re-driven")
+
+// Enqueue stores the message for delivery. Call it with the transaction that
+// makes the change the message is about.
+func Enqueue(tx *gorm.DB, msg mailer.Message) error {
+	return tx.Create(&model.OutboxMessage{
+		Recipient:     msg.To,
+		Subject:       msg.Subject,
+		Body:          msg.Body,
+		HTMLBody:      msg.HTMLBody,
+		Status:        model.OutboxStatusPending,
+		NextAttemptAt: time.Now(),
+	}).Error
+}
+
+// Redrive puts a dead message back in the queue with a fresh attempt budget.
+func Redrive(db *gorm.DB, outboxMessageID uint) (*model.OutboxMessage, error) {
+	var msg model.OutboxMessage
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&msg, outboxMessageID).Error; err != nil {
+			return err
+		}
+		if msg.Status != model.OutboxStatusDead {
+			return ErrNotRedrivable
+		}
+
+		msg.Status = model.OutboxStatusPending
+		msg.Attempts = 0
+		msg.NextAttemptAt = time.Now()
+		msg.LockedUntil = nil
+
+		return tx.Save(&msg).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &msg, nil
+}
@@ -0,0 +1,158 @@
+package outbox
+
+import (
+	"context"
+	"mini-project/mailer"
+	"mini-project/model"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WorkerConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	// Lease is how long a claimed message is reserved for the worker that
+	// claimed it. It must comfortably exceed the time a send can take.
+	Lease time.Duration
+}
+
+// Worker delivers pending outbox messages. Several workers may run at once.
+// A batch is claimed in a short transaction that takes a lease on each
+// message and counts the attempt; the mail is sent after that transaction
+// commits, so no row lock is held across SMTP round trips. A worker that dies
+// mid-batch leaves its messages to be claimed again when the lease expires.
+type Worker struct {
+	db     *gorm.DB
+	mailer mailer.Mailer
+	config WorkerConfig
+}
+
+func NewWorker(db *gorm.DB, m mailer.Mailer, config WorkerConfig) *Worker {
+	return &Worker{db: db, mailer: m, config: config}
+}
+
+// Run polls for due messages until the context is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.deliverBatch(); err != nil {
+			logrus.Errorf("Error delivering outbox messages: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) deliverBatch() error {
+	messages, err := w.claim()
+	if err != nil {
+		return err
+	}
+
+	for i := range messages {
+		if err := w.deliver(&messages[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// claim leases up to BatchSize due messages to this worker and counts the
+// delivery attempt.
+func (w *Worker) claim() ([]model.OutboxMessage, error) {
+	var messages []model.OutboxMessage
+
+	err := w.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", model.OutboxStatusPending, now).
+			Where("locked_until IS NULL OR locked_until <= ?", now).
+			Order("next_attempt_at").
+			Limit(w.config.BatchSize).
+			Find(&messages).Error
+		if err != nil || len(messages) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(messages))
+		lockedUntil := now.Add(w.config.Lease)
+		for i := range messages {
+			ids[i] = messages[i].OutboxMessageID
+			messages[i].Attempts++
+			messages[i].LockedUntil = &lockedUntil
+		}
+
+		return tx.Model(&model.OutboxMessage{}).
+			Where("outbox_message_id IN ?", ids).
+			Updates(map[string]interface{}{
+				"attempts":     gorm.Expr("attempts + 1"),
+				"locked_until": lockedUntil,
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// deliver sends a claimed message and records the outcome. The update only
+// applies while the message is still on the attempt this worker claimed, so a
+// worker whose lease ran out cannot overwrite the result of the next one.
+func (w *Worker) deliver(msg *model.OutboxMessage) error {
+	err := w.mailer.Send(mailer.Message{
+		To:       msg.Recipient,
+		Subject:  msg.Subject,
+		Body:     msg.Body,
+		HTMLBody: msg.HTMLBody,
+	})
+
+	updates := map[string]interface{}{"locked_until": nil}
+	switch {
+	case err == nil:
+		// Sent mail is not needed for a re-drive, and the bodies may hold
+		// one-time links that should not outlive their delivery.
+		updates["status"] = model.OutboxStatusSent
+		updates["sent_at"] = time.Now()
+		updates["last_error"] = ""
+		updates["body"] = ""
+		updates["html_body"] = ""
+	case msg.Attempts >= w.config.MaxAttempts:
+		updates["status"] = model.OutboxStatusDead
+		updates["last_error"] = err.Error()
+		logrus.Warnf("Outbox message %d is dead after %d attempts: %v", msg.OutboxMessageID, msg.Attempts, err)
+	default:
+		updates["next_attempt_at"] = time.Now().Add(w.backoff(msg.Attempts))
+		updates["last_error"] = err.Error()
+	}
+
+	return w.db.Model(&model.OutboxMessage{}).
+		Where("outbox_message_id = ? AND attempts = ?", msg.OutboxMessageID, msg.Attempts).
+		Updates(updates).Error
+}
+
+// backoff doubles the base delay for every failed attempt, up to MaxBackoff.
+func (w *Worker) backoff(attempts int) time.Duration {
+	delay := w.config.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= w.config.MaxBackoff {
+			return w.config.MaxBackoff
+		}
+	}
+
+	return delay
+}
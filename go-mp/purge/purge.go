@@ -0,0 +1,112 @@
+// Package purge permanently removes soft-deleted equipment and rentals once
+// they are older than the retention window, and login attempts and audit log
+// entries once they are older than theirs.
+//
+// Only cancelled or returned rentals are purged, since an active one still
+// holds a deposit. Their ledger journal entries are kept for good: the ledger
+// is immutable and refers to rentals by a plain ID without a foreign key, so
+// the money trail outlives the rental record. Equipment is only purged once
+// no rental, deleted or not, refers to it.
+package purge
+
+import (
+	"context"
+	"mini-project/model"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type Config struct {
+	Interval time.Duration
+	// Retention is how long deleted records are kept before they are purged.
+	Retention time.Duration
+	// LoginAttemptRetention and AuditLogRetention are how long login attempts
+	// and audit log entries are kept. Zero keeps them forever.
+	LoginAttemptRetention time.Duration
+	AuditLogRetention     time.Duration
+}
+
+type Job struct {
+	db     *gorm.DB
+	config Config
+}
+
+func NewJob(db *gorm.DB, config Config) *Job {
+	return &Job{db: db, config: config}
+}
+
+// Run purges expired records every interval until the context is cancelled.
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.Purge(time.Now()); err != nil {
+			logrus.Errorf("Error purging deleted records: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge removes the records deleted before now minus the retention window,
+// and the login attempts and audit log entries past their own.
+func (j *Job) Purge(now time.Time) error {
+	cutoff := now.Add(-j.config.Retention)
+
+	return j.db.Transaction(func(tx *gorm.DB) error {
+		rentals := tx.Unscoped().
+			Where("deleted_at < ?", cutoff).
+			Where("rental_status IN ?", model.InactiveRentalStatuses).
+			Delete(&model.RentalHistory{})
+		if rentals.Error != nil {
+			return rentals.Error
+		}
+
+		// Rentals purged above no longer hold on to their equipment.
+		equipment := tx.Unscoped().
+			Where("deleted_at < ?", cutoff).
+			Where("NOT EXISTS (SELECT 1 FROM rental_histories WHERE rental_histories.equipment_id = equipment.equipment_id)").
+			Delete(&model.Equipment{})
+		if equipment.Error != nil {
+			return equipment.Error
+		}
+
+		if rentals.RowsAffected > 0 || equipment.RowsAffected > 0 {
+			logrus.Infof("Purged %d rentals and %d equipment items deleted before %s",
+				rentals.RowsAffected, equipment.RowsAffected, cutoff.Format(time.RFC3339))
+		}
+
+		if err := purgeOlderThan(tx, &model.LoginAttempt{}, "login attempts", now, j.config.LoginAttemptRetention); err != nil {
+			return err
+		}
+
+		return purgeOlderThan(tx, &model.AuditLog{}, "audit log entries", now, j.config.AuditLogRetention)
+	})
+}
+
+// purgeOlderThan removes the rows of value's table created before now minus
+// retention. A zero retention keeps every row.
+func purgeOlderThan(tx *gorm.DB, value interface{}, name string, now time.Time, retention time.Duration) error {
+	if retention <= 0 {
+		return nil
+	}
+
+	cutoff := now.Add(-retention)
+	result := tx.Where("created_at < ?", cutoff).Delete(value)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		logrus.Infof("Purged %d %s created before %s", result.RowsAffected, name, cutoff.Format(time.RFC3339))
+	}
+
+	return nil
+}
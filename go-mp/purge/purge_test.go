@@ -0,0 +1,140 @@
+package purge
+
+import (
+	"mini-project/ledger"
+	"mini-project/model"
+	"mini-project/testdb"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestPurgeRemovesExpiredRecordsAndKeepsLedger(t *testing.T) {
+	db := testdb.Open(t)
+
+	user := testdb.Renter(t, db, 1000)
+
+	equipment := model.Equipment{Name: "Drill", Availability: true, RentalCosts: 500, Category: "tools"}
+	if err := db.Create(&equipment).Error; err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	returned := model.RentalHistory{UserID: user.UserID, EquipmentID: equipment.EquipmentID, StartDate: start, EndDate: start.AddDate(0, 0, 2), RentalStatus: model.RentalStatusReturned}
+	active := model.RentalHistory{UserID: user.UserID, EquipmentID: equipment.EquipmentID, StartDate: start.AddDate(0, 1, 0), EndDate: start.AddDate(0, 1, 2), RentalStatus: model.RentalStatusConfirmed}
+	if err := db.Create(&returned).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&active).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := ledger.HoldRentalDeposit(tx, user.UserID, returned.RentalHistoryID, 500); err != nil {
+			return err
+		}
+		_, err := ledger.SettleRentalHold(tx, returned.RentalHistoryID, 500)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Delete(&returned).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(&active).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(&equipment).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	job := NewJob(db, Config{Interval: time.Hour, Retention: 24 * time.Hour})
+
+	if err := job.Purge(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if n := countUnscoped(t, db, &model.RentalHistory{}); n != 2 {
+		t.Fatalf("purged rentals inside the retention window: %d left, want 2", n)
+	}
+
+	if err := job.Purge(time.Now().Add(48 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	var left []model.RentalHistory
+	if err := db.Unscoped().Find(&left).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 1 || left[0].RentalHistoryID != active.RentalHistoryID {
+		t.Errorf("rentals left after purge = %+v, want only the active one", left)
+	}
+	if n := countUnscoped(t, db, &model.Equipment{}); n != 1 {
+		t.Errorf("equipment left = %d, want 1 while a rental still refers to it", n)
+	}
+
+	var entries int64
+	if err := db.Model(&model.JournalEntry{}).Where("rental_history_id = ?", returned.RentalHistoryID).Count(&entries).Error; err != nil {
+		t.Fatal(err)
+	}
+	if entries != 2 {
+		t.Errorf("journal entries of the purged rental = %d, want 2", entries)
+	}
+
+	// Once the last rental is gone the equipment is purged too.
+	if err := db.Unscoped().Model(&active).Update("rental_status", model.RentalStatusCancelled).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := job.Purge(time.Now().Add(48 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if n := countUnscoped(t, db, &model.RentalHistory{}); n != 0 {
+		t.Errorf("rentals left = %d, want 0", n)
+	}
+	if n := countUnscoped(t, db, &model.Equipment{}); n != 0 {
+		t.Errorf("equipment left = %d, want 0", n)
+	}
+}
+
+func TestPurgeRemovesExpiredLoginAttemptsAndAuditLogs(t *testing.T) {
+	db := testdb.Open(t)
+
+	now := time.Now()
+	for _, created := range []time.Time{now.Add(-48 * time.Hour), now} {
+		if err := db.Create(&model.LoginAttempt{Email: "renter@example.com", IPAddress: "192.0.2.1", CreatedAt: created}).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Create(&model.AuditLog{Event: model.AuditLoginFailed, Email: "renter@example.com", IPAddress: "192.0.2.1", CreatedAt: created}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	job := NewJob(db, Config{Interval: time.Hour, LoginAttemptRetention: 24 * time.Hour})
+	if err := job.Purge(now); err != nil {
+		t.Fatal(err)
+	}
+	if n := countUnscoped(t, db, &model.LoginAttempt{}); n != 1 {
+		t.Errorf("login attempts left = %d, want 1", n)
+	}
+	if n := countUnscoped(t, db, &model.AuditLog{}); n != 2 {
+		t.Errorf("audit log entries left = %d, want 2 without an audit log retention", n)
+	}
+
+	job = NewJob(db, Config{Interval: time.Hour, AuditLogRetention: 24 * time.Hour})
+	if err := job.Purge(now); err != nil {
+		t.Fatal(err)
+	}
+	if n := countUnscoped(t, db, &model.AuditLog{}); n != 1 {
+		t.Errorf("audit log entries left = %d, want 1", n)
+	}
+}
+
+func countUnscoped(t *testing.T, db *gorm.DB, value interface{}) int64 {
+	t.Helper()
+
+	var n int64
+	if err := db.Unscoped().Model(value).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return n
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"mini-project/model"
+
+	"gorm.io/gorm"
+)
+
+// AuditLogFilter narrows an audit log listing. Zero values and nil pointers
+// do not filter.
+type AuditLogFilter struct {
+	Event  string
+	UserID *uint
+}
+
+var auditLogSortKeys = sortKeys[model.AuditLog]{
+	"audit_log_id": func(a model.AuditLog) interface{} { return a.AuditLogID },
+	"created_at":   func(a model.AuditLog) interface{} { return a.CreatedAt },
+}
+
+// ListAuditLogs returns one page of the audit log entries matching filter.
+func ListAuditLogs(tx *gorm.DB, filter AuditLogFilter, req PageRequest) (Page[model.AuditLog], error) {
+	query := tx.Model(&model.AuditLog{})
+
+	if filter.Event != "" {
+		query = query.Where("event = ?", filter.Event)
+	}
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+
+	return listPage(query, auditLogSortKeys, "audit_log_id", func(a model.AuditLog) uint { return a.AuditLogID }, req)
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"mini-project/model"
+
+	"gorm.io/gorm"
+)
+
+// EquipmentFilter narrows an equipment listing. Zero values and nil pointers
+// do not filter.
+type EquipmentFilter struct {
+	Category  string
+	Available *bool
+	// MinCost and MaxCost bound the rental costs in cents, inclusive.
+	MinCost *int64
+	MaxCost *int64
+	// Search matches equipment whose name contains it, ignoring case.
+	Search string
+	// IncludeDeleted lists soft-deleted equipment too.
+	IncludeDeleted bool
+}
+
+var equipmentSortKeys = sortKeys[model.Equipment]{
+	"equipment_id": func(e model.Equipment) interface{} { return e.EquipmentID },
+	"name":         func(e model.Equipment) interface{} { return e.Name },
+	"availability": func(e model.Equipment) interface{} { return e.Availability },
+	"rental_costs": func(e model.Equipment) interface{} { return e.RentalCosts },
+	"category":     func(e model.Equipment) interface{} { return e.Category },
+}
+
+// ListEquipment returns one page of the equipment matching filter.
+func ListEquipment(tx *gorm.DB, filter EquipmentFilter, req PageRequest) (Page[model.Equipment], error) {
+	query := tx.Model(&model.Equipment{})
+
+	if filter.IncludeDeleted {
+		query = query.Unscoped()
+	}
+
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if filter.Available != nil {
+		query = query.Where("availability = ?", *filter.Available)
+	}
+	if filter.MinCost != nil {
+		query = query.Where("rental_costs >= ?", *filter.MinCost)
+	}
+	if filter.MaxCost != nil {
+		query = query.Where("rental_costs <= ?", *filter.MaxCost)
+	}
+	if filter.Search != "" {
+		query = query.Where("name ILIKE ?", containsPattern(filter.Search))
+	}
+
+	return listPage(query, equipmentSortKeys, "equipment_id", func(e model.Equipment) uint { return e.EquipmentID }, req)
+}
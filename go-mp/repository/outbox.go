@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"mini-project/model"
+
+	"gorm.io/gorm"
+)
+
+var outboxSortKeys = sortKeys[model.OutboxMessage]{
+	"outbox_message_id": func(m model.OutboxMessage) interface{} { return m.OutboxMessageID },
+	"next_attempt_at":   func(m model.OutboxMessage) interface{} { return m.NextAttemptAt },
+	"created_at":        func(m model.OutboxMessage) interface{} { return m.CreatedAt },
+}
+
+// ListOutboxMessages returns one page of the outbox messages with status,
+// leaving out their bodies.
+func ListOutboxMessages(tx *gorm.DB, status string, req PageRequest) (Page[model.OutboxMessage], error) {
+	query := tx.Model(&model.OutboxMessage{}).
+		Omit("body", "html_body").
+		Where("status = ?", status)
+
+	return listPage(query, outboxSortKeys, "outbox_message_id", func(m model.OutboxMessage) uint { return m.OutboxMessageID }, req)
+}
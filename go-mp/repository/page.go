@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidSort   = errors.New("invalid sort")
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// PageRequest selects one page of a listing. Sort is a column name, prefixed
+// with "-" for descending order; Cursor is the NextCursor of the previous page
+// and must come from a listing with the same sort.
+type PageRequest struct {
+	Sort         string
+	Cursor       string
+	Limit        int
+	IncludeTotal bool
+}
+
+// Page is one page of a listing. NextCursor is empty on the last page and
+// Total is only counted when the request asked for it.
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+	Total      *int64
+}
+
+// sortKeys maps the sortable columns of a listing to the value of the column
+// in a row, which is stored in the cursor.
+type sortKeys[T any] map[string]func(T) interface{}
+
+type cursor struct {
+	Sort  string          `json:"s"`
+	Value json.RawMessage `json:"v"`
+	ID    uint            `json:"id"`
+}
+
+// listPage runs query as a keyset paginated listing. Rows are ordered by the
+// requested column and then by idColumn, so the cursor stays stable while
+// rows are inserted or deleted. The associations in preloads are loaded for
+// the rows of the page.
+func listPage[T any](query *gorm.DB, keys sortKeys[T], idColumn string, id func(T) uint, req PageRequest, preloads ...string) (Page[T], error) {
+	var page Page[T]
+
+	column, desc := strings.TrimPrefix(req.Sort, "-"), strings.HasPrefix(req.Sort, "-")
+	if column == "" {
+		column = idColumn
+	}
+	value, ok := keys[column]
+	if !ok {
+		return page, fmt.Errorf("%w: %q", ErrInvalidSort, column)
+	}
+
+	query = query.Session(&gorm.Session{})
+	if req.IncludeTotal {
+		var total int64
+		if err := query.Model(new(T)).Count(&total).Error; err != nil {
+			return page, err
+		}
+		page.Total = &total
+	}
+
+	direction, op := "ASC", ">"
+	if desc {
+		direction, op = "DESC", "<"
+	}
+
+	if req.Cursor != "" {
+		after, err := decodeCursor(req.Cursor, req.Sort)
+		if err != nil {
+			return page, err
+		}
+
+		// Decode the value into the Go type of the column so that it binds
+		// as the right SQL type.
+		var zero T
+		target := reflect.New(reflect.TypeOf(value(zero)))
+		if err := json.Unmarshal(after.Value, target.Interface()); err != nil {
+			return page, ErrInvalidCursor
+		}
+		v := target.Elem().Interface()
+
+		if column == idColumn {
+			query = query.Where(fmt.Sprintf("%s %s ?", idColumn, op), after.ID)
+		} else {
+			query = query.Where(fmt.Sprintf("(%s %s ? OR (%s = ? AND %s %s ?))", column, op, column, idColumn, op), v, v, after.ID)
+		}
+	}
+
+	query = query.Order(fmt.Sprintf("%s %s", column, direction))
+	if column != idColumn {
+		query = query.Order(fmt.Sprintf("%s %s", idColumn, direction))
+	}
+
+	for _, preload := range preloads {
+		query = query.Preload(preload)
+	}
+
+	// Fetch one extra row to learn whether there is a next page.
+	page.Items = []T{}
+	if err := query.Limit(req.Limit + 1).Find(&page.Items).Error; err != nil {
+		return page, err
+	}
+	if len(page.Items) > req.Limit {
+		page.Items = page.Items[:req.Limit]
+		last := page.Items[len(page.Items)-1]
+
+		next, err := encodeCursor(req.Sort, value(last), id(last))
+		if err != nil {
+			return page, err
+		}
+		page.NextCursor = next
+	}
+
+	return page, nil
+}
+
+func encodeCursor(sort string, value interface{}, id uint) (string, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := json.Marshal(cursor{Sort: sort, Value: raw, ID: id})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(s, sort string) (cursor, error) {
+	var c cursor
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(b, &c); err != nil || c.Sort != sort {
+		return c, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// containsPattern returns an ILIKE pattern matching values that contain s,
+// with the wildcards in s escaped.
+func containsPattern(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + replacer.Replace(s) + "%"
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"mini-project/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RentalFilter narrows a rental listing. Zero values and nil pointers do not
+// filter.
+type RentalFilter struct {
+	UserID      *uint
+	EquipmentID *uint
+	Statuses    []string
+	// From and To select the rentals whose date range intersects [From, To).
+	From *time.Time
+	To   *time.Time
+	// IncludeDeleted lists soft-deleted rentals too.
+	IncludeDeleted bool
+}
+
+var rentalSortKeys = sortKeys[model.RentalHistory]{
+	"rental_history_id": func(r model.RentalHistory) interface{} { return r.RentalHistoryID },
+	"start_date":        func(r model.RentalHistory) interface{} { return r.StartDate },
+	"end_date":          func(r model.RentalHistory) interface{} { return r.EndDate },
+	"rental_status":     func(r model.RentalHistory) interface{} { return r.RentalStatus },
+}
+
+// ListRentals returns one page of the rentals matching filter, with summaries
+// of their user and equipment.
+func ListRentals(tx *gorm.DB, filter RentalFilter, req PageRequest) (Page[model.RentalHistory], error) {
+	query := tx.Model(&model.RentalHistory{})
+
+	if filter.IncludeDeleted {
+		query = query.Unscoped()
+	}
+
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.EquipmentID != nil {
+		query = query.Where("equipment_id = ?", *filter.EquipmentID)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("rental_status IN ?", filter.Statuses)
+	}
+	if filter.From != nil {
+		query = query.Where("end_date > ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("start_date < ?", *filter.To)
+	}
+
+	return listPage(query, rentalSortKeys, "rental_history_id", func(r model.RentalHistory) uint { return r.RentalHistoryID }, req, "User", "Equipment")
+}
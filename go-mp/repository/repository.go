@@ -0,0 +1,65 @@
+// Package repository loads the rows handlers look up by ID. Missing rows are
+// reported as *NotFoundError so that callers can tell them apart from
+// database failures, which are returned wrapped with the resource and ID.
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"mini-project/model"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ResourceUser      = "user"
+	ResourceEquipment = "equipment"
+	ResourceRental    = "rental"
+)
+
+// NotFoundError reports that no row of Resource has the ID.
+type NotFoundError struct {
+	Resource string
+	ID       uint
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s %d not found", e.Resource, e.ID)
+}
+
+// IsNotFound reports whether err is a *NotFoundError for resource.
+func IsNotFound(err error, resource string) bool {
+	var notFound *NotFoundError
+	return errors.As(err, &notFound) && notFound.Resource == resource
+}
+
+// FindUser loads the user with id. Pass a locking query as tx to lock the row.
+func FindUser(tx *gorm.DB, id uint) (model.User, error) {
+	var user model.User
+	err := first(tx, &user, ResourceUser, id)
+	return user, err
+}
+
+func FindEquipment(tx *gorm.DB, id uint) (model.Equipment, error) {
+	var equipment model.Equipment
+	err := first(tx, &equipment, ResourceEquipment, id)
+	return equipment, err
+}
+
+func FindRental(tx *gorm.DB, id uint) (model.RentalHistory, error) {
+	var rental model.RentalHistory
+	err := first(tx, &rental, ResourceRental, id)
+	return rental, err
+}
+
+func first(tx *gorm.DB, dest interface{}, resource string, id uint) error {
+	err := tx.First(dest, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &NotFoundError{Resource: resource, ID: id}
+	}
+	if err != nil {
+		return fmt.Errorf("find %s %d: %w", resource, id, err)
+	}
+
+	return nil
+}
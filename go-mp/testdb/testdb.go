@@ -0,0 +1,91 @@
+// Package testdb provides the Postgres database used by tests that exercise
+// row locking and other behaviour an in-memory fake cannot reproduce.
+package testdb
+
+import (
+	"fmt"
+	"mini-project/ledger"
+	"mini-project/migrate"
+	"mini-project/model"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Open connects to the database named by TEST_DATABASE_URL, migrates it and
+// empties every table. The test is skipped when the variable is not set. The
+// database is shared by every package that uses it, so run those tests with
+// go test -p 1.
+func Open(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db := Connect(t)
+	if err := migrate.Run(db); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+
+	var tables []string
+	if err := db.Raw("SELECT tablename FROM pg_tables WHERE schemaname = current_schema()").Scan(&tables).Error; err != nil {
+		t.Fatalf("list test tables: %v", err)
+	}
+	for _, table := range tables {
+		if err := db.Exec("TRUNCATE TABLE " + db.Statement.Quote(table) + " RESTART IDENTITY CASCADE").Error; err != nil {
+			t.Fatalf("truncate %s: %v", table, err)
+		}
+	}
+
+	return db
+}
+
+// Connect connects to the database named by TEST_DATABASE_URL without
+// touching its schema, for tests that set up the schema themselves. The test
+// is skipped when the variable is not set.
+func Connect(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("connect to test database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("connect to test database: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	return db
+}
+
+var renters atomic.Int64
+
+// Renter creates a renter with its own email address and tops up its wallet
+// with balance cents, unless balance is zero.
+func Renter(t *testing.T, db *gorm.DB, balance int64) model.User {
+	t.Helper()
+
+	user := model.User{
+		Email:    fmt.Sprintf("renter%d@example.com", renters.Add(1)),
+		Password: "x",
+		Role:     model.RoleRenter,
+	}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("create renter: %v", err)
+	}
+
+	if balance != 0 {
+		if _, err := ledger.TopUp(db, user.UserID, balance); err != nil {
+			t.Fatalf("top up renter: %v", err)
+		}
+	}
+
+	return user
+}
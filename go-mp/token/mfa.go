@@ -0,0 +1,37 @@
+package token
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// IssueMFAChallengeToken signs a token proving that the user passed the
+// password step of a login. It can only be exchanged at /login/mfa, together
+// with a second factor, for a session.
+func (s *Service) IssueMFAChallengeToken(userID uint, ttl time.Duration) (string, error) {
+	now := time.Now()
+
+	return s.Sign(jwt.MapClaims{
+		"typ": TypeMFAChallenge,
+		"sub": userID,
+		"iat": now.Unix(),
+		"exp": now.Add(ttl).Unix(),
+	})
+}
+
+// ParseMFAChallengeToken verifies the token and returns the user ID it was
+// issued for.
+func (s *Service) ParseMFAChallengeToken(tokenString string) (uint, error) {
+	claims, err := s.Parse(tokenString, TypeMFAChallenge)
+	if err != nil {
+		return 0, err
+	}
+
+	sub, ok := claims["sub"].(float64)
+	if !ok || sub <= 0 {
+		return 0, ErrInvalidToken
+	}
+
+	return uint(sub), nil
+}
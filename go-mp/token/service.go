@@ -0,0 +1,196 @@
+// Package token issues and verifies the JWTs used by the API. Tokens are
+// signed with an asymmetric key and carry its kid, so other services can
+// verify them with the keys published at /.well-known/jwks.json.
+package token
+
+import (
+	"errors"
+	"fmt"
+	"mini-project/model"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	TypeAccess            = "access"
+	TypeEmailVerification = "email_verification"
+	TypeMFAChallenge      = "mfa_challenge"
+)
+
+// Authentication method references carried in the amr claim (RFC 8176).
+const (
+	MethodPassword = "pwd"
+	MethodOTP      = "otp"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Service signs tokens with one key and verifies them against every loaded key.
+type Service struct {
+	signing *Key
+	keys    map[string]*Key
+}
+
+// NewService uses the key with signingKeyID for new tokens. All keys,
+// including the signing key, are accepted for verification.
+func NewService(signingKeyID string, keys ...*Key) (*Service, error) {
+	s := &Service{keys: make(map[string]*Key, len(keys))}
+	for _, key := range keys {
+		if _, exists := s.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		s.keys[key.ID] = key
+	}
+
+	signing, ok := s.keys[signingKeyID]
+	if !ok {
+		return nil, fmt.Errorf("signing key %q not found", signingKeyID)
+	}
+	if signing.Private == nil {
+		return nil, fmt.Errorf("signing key %q has no private key", signingKeyID)
+	}
+	s.signing = signing
+
+	return s, nil
+}
+
+// AccessClaims is the identity carried by an access token.
+type AccessClaims struct {
+	UserID    uint
+	Email     string
+	Roles     []string
+	SessionID string
+	// MFA is true when the session was opened with a second factor.
+	MFA bool
+}
+
+// IssueAccessToken signs an access token for the user's session. mfa records
+// whether the login passed a second factor.
+func (s *Service) IssueAccessToken(user model.User, sessionID string, mfa bool, ttl time.Duration) (string, error) {
+	now := time.Now()
+
+	amr := []string{MethodPassword}
+	if mfa {
+		amr = append(amr, MethodOTP)
+	}
+
+	return s.Sign(jwt.MapClaims{
+		"typ":   TypeAccess,
+		"sub":   user.UserID,
+		"user":  user.Email,
+		"roles": []string{user.Role},
+		"sid":   sessionID,
+		"amr":   amr,
+		"iat":   now.Unix(),
+		"exp":   now.Add(ttl).Unix(),
+	})
+}
+
+// ParseAccessToken verifies an access token and extracts its claims.
+func (s *Service) ParseAccessToken(tokenString string) (*AccessClaims, error) {
+	claims, err := s.Parse(tokenString, TypeAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, ok := claims["sub"].(float64)
+	if !ok || sub <= 0 {
+		return nil, ErrInvalidToken
+	}
+
+	email, ok := claims["user"].(string)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	rawRoles, ok := claims["roles"].([]interface{})
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	roles := make([]string, 0, len(rawRoles))
+	for _, r := range rawRoles {
+		role, ok := r.(string)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+		roles = append(roles, role)
+	}
+
+	sessionID, ok := claims["sid"].(string)
+	if !ok || sessionID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	// Tokens without amr predate MFA and count as password-only.
+	mfa := false
+	rawAMR, _ := claims["amr"].([]interface{})
+	for _, m := range rawAMR {
+		if m == MethodOTP {
+			mfa = true
+		}
+	}
+
+	return &AccessClaims{
+		UserID:    uint(sub),
+		Email:     email,
+		Roles:     roles,
+		SessionID: sessionID,
+		MFA:       mfa,
+	}, nil
+}
+
+// Sign signs the claims with the current signing key and sets its kid header.
+func (s *Service) Sign(claims jwt.MapClaims) (string, error) {
+	t := jwt.NewWithClaims(s.signing.Method, claims)
+	t.Header["kid"] = s.signing.ID
+
+	return t.SignedString(s.signing.Private)
+}
+
+// Parse verifies the signature and expiry of the token and checks that its
+// typ claim matches tokenType. The algorithm must match the one of the key
+// named by the kid header.
+func (s *Service) Parse(tokenString, tokenType string) (jwt.MapClaims, error) {
+	var key *Key
+
+	t, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+
+		var ok bool
+		key, ok = s.keys[kid]
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+		if t.Method.Alg() != key.Method.Alg() {
+			return nil, ErrInvalidToken
+		}
+
+		return key.Public, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}))
+	if err != nil || !t.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	claims, ok := t.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	if typ, _ := claims["typ"].(string); typ != tokenType {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+// JWKS returns the public verification keys, sorted by kid.
+func (s *Service) JWKS() JWKSet {
+	set := JWKSet{Keys: make([]JWK, 0, len(s.keys))}
+	for _, key := range s.keys {
+		set.Keys = append(set.Keys, toJWK(key))
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].KeyID < set.Keys[j].KeyID })
+
+	return set
+}
@@ -0,0 +1,46 @@
+package token
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// IssueEmailVerificationToken signs a token proving that whoever holds it can
+// read mail sent to email. It is only valid while the user still has that
+// address.
+func (s *Service) IssueEmailVerificationToken(userID uint, email string, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	signed, err := s.Sign(jwt.MapClaims{
+		"typ":   TypeEmailVerification,
+		"sub":   userID,
+		"email": email,
+		"iat":   now.Unix(),
+		"exp":   expiresAt.Unix(),
+	})
+
+	return signed, expiresAt, err
+}
+
+// ParseEmailVerificationToken verifies the token and returns the user ID and
+// email address it was issued for.
+func (s *Service) ParseEmailVerificationToken(tokenString string) (uint, string, error) {
+	claims, err := s.Parse(tokenString, TypeEmailVerification)
+	if err != nil {
+		return 0, "", err
+	}
+
+	sub, ok := claims["sub"].(float64)
+	if !ok || sub <= 0 {
+		return 0, "", ErrInvalidToken
+	}
+
+	email, ok := claims["email"].(string)
+	if !ok || email == "" {
+		return 0, "", ErrInvalidToken
+	}
+
+	return uint(sub), email, nil
+}
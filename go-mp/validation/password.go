@@ -0,0 +1,65 @@
+package validation
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// breachedPasswords is a list of the most common passwords found in public
+// breach corpora, one per line and lowercase. It is checked offline so no
+// password ever leaves the server.
+//
+//go:embed breached_passwords.txt
+var breachedPasswords []byte
+
+var (
+	breachedOnce sync.Once
+	breachedSet  map[string]struct{}
+)
+
+type PasswordPolicy struct {
+	MinLength int
+	// MaxLength guards bcrypt, which ignores everything past 72 bytes.
+	MaxLength      int
+	RejectBreached bool
+}
+
+// Password checks value against the policy.
+func (e *Errors) Password(field, value string, policy PasswordPolicy) {
+	if value == "" {
+		e.Add(field, RuleRequired, field+" is required")
+		return
+	}
+
+	if utf8.RuneCountInString(value) < policy.MinLength {
+		e.Add(field, RuleMin, fmt.Sprintf("%s must be at least %d characters long", field, policy.MinLength))
+	}
+	if policy.MaxLength > 0 && len(value) > policy.MaxLength {
+		e.Add(field, RuleMax, fmt.Sprintf("%s must be at most %d bytes long", field, policy.MaxLength))
+	}
+	if policy.RejectBreached && IsBreachedPassword(value) {
+		e.Add(field, RuleBreached, field+" appears in a list of breached passwords; choose another one")
+	}
+}
+
+// IsBreachedPassword reports whether password, ignoring case, is on the
+// bundled breached password list.
+func IsBreachedPassword(password string) bool {
+	breachedOnce.Do(func() {
+		breachedSet = make(map[string]struct{})
+		scanner := bufio.NewScanner(bytes.NewReader(breachedPasswords))
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+				breachedSet[line] = struct{}{}
+			}
+		}
+	})
+
+	_, found := breachedSet[strings.ToLower(password)]
+	return found
+}
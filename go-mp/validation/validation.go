@@ -0,0 +1,71 @@
+// Package validation checks request input and reports every problem with the
+// field it belongs to, so clients can show errors next to the right input.
+package validation
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// Rule names reported in FieldError.Rule. They are spelled like the rules in
+// validate struct tags, so a field breaks the same rule whether it is checked
+// by a tag or by code: min and max bound string lengths as well as numbers.
+const (
+	RuleRequired        = "required"
+	RuleRequiredWithout = "required_without"
+	RuleEmail           = "email"
+	RuleMin             = "min"
+	RuleMax             = "max"
+	RuleGt              = "gt"
+	RuleOneOf           = "oneof"
+	RuleGtField         = "gtfield"
+	RuleNotPast         = "not_past"
+	RuleUnique          = "unique"
+	RuleBreached        = "not_breached"
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Errors collects the field errors of one request. It is an error so it can
+// be returned from transactions and matched with errors.As.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		messages = append(messages, fieldErr.Field+": "+fieldErr.Message)
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Add records a problem with field.
+func (e *Errors) Add(field, rule, message string) {
+	*e = append(*e, FieldError{Field: field, Rule: rule, Message: message})
+}
+
+// Err returns nil when there are no errors, so callers can return it directly.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+// Email checks that value is a single bare address such as user@example.com.
+func (e *Errors) Email(field, value string) {
+	if value == "" {
+		e.Add(field, RuleRequired, field+" is required")
+		return
+	}
+
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Address != value || !strings.Contains(value[strings.LastIndex(value, "@")+1:], ".") {
+		e.Add(field, RuleEmail, field+" must be a valid email address")
+	}
+}
@@ -0,0 +1,227 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Validator checks request bodies against the rules in their validate struct
+// tags and is installed as echo's Validator. Rules are comma separated:
+//
+//	required      the value must not be the zero value (or blank, for strings)
+//	required_without=F
+//	              required unless field F is set
+//	omitempty     skip the remaining rules when the value is the zero value
+//	min=N, max=N  bounds on the length of a string or the value of a number
+//	gt=N          the number must be greater than N
+//	email         the string must be a bare email address
+//	oneof=a b     the string must be one of the space separated values
+//	gtfield=F     the time must be after the one in field F
+//	not_past      the time must not be before the start of today (UTC)
+//	password      the string must satisfy the password policy
+//
+// Fields are reported by their JSON name. The tags of each struct type are
+// checked the first time it is validated; a tag with an unknown rule or a
+// malformed parameter makes Validate return an error instead of Errors.
+type Validator struct {
+	passwordPolicy PasswordPolicy
+	checked        sync.Map // reflect.Type -> error
+}
+
+func NewValidator(passwordPolicy PasswordPolicy) *Validator {
+	return &Validator{passwordPolicy: passwordPolicy}
+}
+
+// Validate returns Errors listing every failing field and rule, or nil.
+func (v *Validator) Validate(i interface{}) error {
+	value := reflect.Indirect(reflect.ValueOf(i))
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	structType := value.Type()
+	if err := v.checkTags(structType); err != nil {
+		return err
+	}
+
+	var errs Errors
+	for n := 0; n < structType.NumField(); n++ {
+		field := structType.Field(n)
+		tag := field.Tag.Get("validate")
+		if tag == "" || !field.IsExported() {
+			continue
+		}
+
+		v.validateField(&errs, value, jsonName(field), value.Field(n), strings.Split(tag, ","))
+	}
+
+	return errs.Err()
+}
+
+func (v *Validator) validateField(errs *Errors, parent reflect.Value, name string, value reflect.Value, rules []string) {
+	zero := isZero(value)
+
+	for _, rule := range rules {
+		rule, param, _ := strings.Cut(rule, "=")
+
+		switch rule {
+		case "omitempty":
+			if zero {
+				return
+			}
+		case RuleRequired:
+			if zero {
+				errs.Add(name, RuleRequired, fmt.Sprintf("%s is required", name))
+				return
+			}
+		case RuleRequiredWithout:
+			if zero && isZero(parent.FieldByName(param)) {
+				otherField, _ := parent.Type().FieldByName(param)
+				errs.Add(name, rule, fmt.Sprintf("%s is required when %s is not set", name, jsonName(otherField)))
+				return
+			}
+		case RuleMin, RuleMax:
+			limit, _ := strconv.ParseInt(param, 10, 64)
+			size, isString := measure(value)
+			switch {
+			case rule == RuleMin && size < limit && isString:
+				errs.Add(name, rule, fmt.Sprintf("%s must be at least %d characters long", name, limit))
+			case rule == RuleMin && size < limit:
+				errs.Add(name, rule, fmt.Sprintf("%s must be at least %d", name, limit))
+			case rule == RuleMax && size > limit && isString:
+				errs.Add(name, rule, fmt.Sprintf("%s must be at most %d characters long", name, limit))
+			case rule == RuleMax && size > limit:
+				errs.Add(name, rule, fmt.Sprintf("%s must be at most %d", name, limit))
+			}
+		case RuleGt:
+			limit, _ := strconv.ParseInt(param, 10, 64)
+			if size, _ := measure(value); size <= limit {
+				errs.Add(name, rule, fmt.Sprintf("%s must be greater than %d", name, limit))
+			}
+		case RuleEmail:
+			errs.Email(name, value.String())
+		case RuleOneOf:
+			allowed := strings.Fields(param)
+			if !contains(allowed, value.String()) {
+				errs.Add(name, rule, fmt.Sprintf("%s must be one of: %s", name, strings.Join(allowed, ", ")))
+			}
+		case RuleGtField:
+			other := parent.FieldByName(param)
+			after, ok := value.Interface().(time.Time)
+			before, otherOK := other.Interface().(time.Time)
+			if ok && otherOK && !after.After(before) {
+				otherField, _ := parent.Type().FieldByName(param)
+				errs.Add(name, rule, fmt.Sprintf("%s must be after %s", name, jsonName(otherField)))
+			}
+		case RuleNotPast:
+			today := time.Now().UTC().Truncate(24 * time.Hour)
+			if t, ok := value.Interface().(time.Time); ok && t.Before(today) {
+				errs.Add(name, rule, fmt.Sprintf("%s must not be in the past", name))
+			}
+		case "password":
+			errs.Password(name, value.String(), v.passwordPolicy)
+		}
+	}
+}
+
+// checkTags reports the first malformed rule in the validate tags of
+// structType. The result is cached per type.
+func (v *Validator) checkTags(structType reflect.Type) error {
+	if err, ok := v.checked.Load(structType); ok {
+		err, _ := err.(error)
+		return err
+	}
+
+	var err error
+	for n := 0; n < structType.NumField() && err == nil; n++ {
+		field := structType.Field(n)
+		tag := field.Tag.Get("validate")
+		if tag == "" || !field.IsExported() {
+			continue
+		}
+
+		for _, rule := range strings.Split(tag, ",") {
+			if err = checkRule(structType, rule); err != nil {
+				err = fmt.Errorf("validation: %s.%s: %w", structType.Name(), field.Name, err)
+				break
+			}
+		}
+	}
+
+	v.checked.Store(structType, err)
+	return err
+}
+
+func checkRule(structType reflect.Type, rule string) error {
+	rule, param, _ := strings.Cut(rule, "=")
+
+	switch rule {
+	case "omitempty", RuleRequired, RuleEmail, RuleNotPast, "password":
+		return nil
+	case RuleMin, RuleMax, RuleGt:
+		if _, err := strconv.ParseInt(param, 10, 64); err != nil {
+			return fmt.Errorf("rule %q needs an integer parameter, got %q", rule, param)
+		}
+	case RuleOneOf:
+		if len(strings.Fields(param)) == 0 {
+			return fmt.Errorf("rule %q needs at least one value", rule)
+		}
+	case RuleGtField, RuleRequiredWithout:
+		if _, ok := structType.FieldByName(param); !ok {
+			return fmt.Errorf("rule %q refers to unknown field %q", rule, param)
+		}
+	default:
+		return fmt.Errorf("unknown rule %q", rule)
+	}
+
+	return nil
+}
+
+// measure returns the rune length of strings and the value of integers.
+func measure(value reflect.Value) (int64, bool) {
+	switch value.Kind() {
+	case reflect.String:
+		return int64(utf8.RuneCountInString(value.String())), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int(), false
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(value.Uint()), false
+	default:
+		return 0, false
+	}
+}
+
+func isZero(value reflect.Value) bool {
+	if value.Kind() == reflect.String {
+		return strings.TrimSpace(value.String()) == ""
+	}
+	if t, ok := value.Interface().(time.Time); ok {
+		return t.IsZero()
+	}
+
+	return value.IsZero()
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+
+	return name
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}